@@ -2,58 +2,106 @@ package auth
 
 import (
 	"backend/models"
-	"log"
-	"os"
+	"backend/utils"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrInvalidPassword = errors.New("invalid password")
+
 func CheckLoginValidity(userData *models.User, loginData *models.LoginUserData) (int64, error) {
-	err := bcrypt.CompareHashAndPassword(
-		[]byte(userData.PasswordHash),
-		[]byte(loginData.Password),
-	)
+	valid, err := utils.VerifyPassword(userData.PasswordHash, loginData.Password)
 
 	if err != nil {
 		return 0, err
 	}
 
+	if !valid {
+		return 0, ErrInvalidPassword
+	}
+
 	return userData.ID, nil
 }
 
 func GenerateJWT(userID int64) (string, error) {
-	var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+	return signJWT(jwt.MapClaims{
+		"user_id": userID,
+	}, 24*time.Hour)
+}
 
-	if len(jwtSecret) == 0 {
-		log.Println("Empty secret")
+// Issued after the password step of a login for accounts with 2FA enabled. It only identifies the user
+// to the second step and is refused everywhere else.
+func GeneratePendingJWT(userID int64) (string, error) {
+	return signJWT(jwt.MapClaims{
+		"user_id":     userID,
+		"2fa_pending": true,
+	}, 5*time.Minute)
+}
+
+// reports whether a token only stands for a half-finished 2FA login
+func IsPendingToken(token *jwt.Token) bool {
+	claims, match := token.Claims.(jwt.MapClaims)
+	if !match {
+		return false
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-	})
+	pending, _ := claims["2fa_pending"].(bool)
+
+	return pending
+}
 
-	tokenStr, err := token.SignedString(jwtSecret)
+// returns the user a pending 2FA token was issued to
+func CheckPendingTokenValidity(tokenStr string) (int64, error) {
+	token, err := CheckTokenValidity(tokenStr)
 
-	return tokenStr, err
+	if err != nil || !token.Valid || !IsPendingToken(token) {
+		return 0, jwt.ErrTokenInvalidClaims
+	}
+
+	userID, match := token.Claims.(jwt.MapClaims)["user_id"].(float64)
+	if !match {
+		return 0, jwt.ErrTokenInvalidClaims
+	}
+
+	return int64(userID), nil
 }
 
-func CheckTokenValidity(tokenStr string) (*jwt.Token, error) {
-	var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// Issued after a first OIDC login for an identity with no account yet, carrying the verified identity
+// to the signup step where the user picks a username. It has no user_id so no route accepts it as a session.
+func GenerateSignupJWT(provider string, subject string, email string, emailVerified bool) (string, error) {
+	return signJWT(jwt.MapClaims{
+		"oidc_signup":    true,
+		"provider":       provider,
+		"subject":        subject,
+		"email":          email,
+		"email_verified": emailVerified,
+	}, 15*time.Minute)
+}
 
-	if len(jwtSecret) == 0 {
-		log.Println("Empty Secret")
+// returns the identity a signup token was issued for
+func CheckSignupTokenValidity(tokenStr string) (string, string, string, bool, error) {
+	token, err := CheckTokenValidity(tokenStr)
+
+	if err != nil || !token.Valid {
+		return "", "", "", false, jwt.ErrTokenInvalidClaims
+	}
+
+	claims, match := token.Claims.(jwt.MapClaims)
+	if !match {
+		return "", "", "", false, jwt.ErrTokenInvalidClaims
 	}
 
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, match := token.Method.(*jwt.SigningMethodHMAC); !match {
-			return nil, jwt.ErrTokenMalformed
-		}
+	signup, _ := claims["oidc_signup"].(bool)
+	provider, _ := claims["provider"].(string)
+	subject, _ := claims["subject"].(string)
+	email, _ := claims["email"].(string)
+	emailVerified, _ := claims["email_verified"].(bool)
 
-		return jwtSecret, nil
-	})
+	if !signup || provider == "" || subject == "" {
+		return "", "", "", false, jwt.ErrTokenInvalidClaims
+	}
 
-	return token, err
+	return provider, subject, email, emailVerified, nil
 }
@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrKeysNotLoaded = errors.New("jwt keys not loaded")
+
+const minSecretLength = 32
+
+type jwtKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+type keyRing struct {
+	signing  *jwtKey
+	verify   map[string]*jwtKey
+	issuer   string
+	audience string
+}
+
+var keys *keyRing
+
+// Loads the token keys from the environment and must be called once at startup. Any missing or
+// unusable key is an error, so the server refuses to start rather than sign with a bad key.
+//
+//	JWT_ALGORITHM                 HS256 (default), RS256 or EdDSA
+//	JWT_SECRET                    the HS256 secret, at least 32 bytes
+//	JWT_PRIVATE_KEY_FILE          PEM private key for RS256/EdDSA
+//	JWT_PREVIOUS_SECRETS          comma-separated secrets still accepted while rotating
+//	JWT_PREVIOUS_PUBLIC_KEY_FILES comma-separated PEM public keys still accepted while rotating
+//	JWT_ISSUER, JWT_AUDIENCE      default "chatit"
+func LoadKeys() error {
+	ring := &keyRing{
+		verify:   map[string]*jwtKey{},
+		issuer:   os.Getenv("JWT_ISSUER"),
+		audience: os.Getenv("JWT_AUDIENCE"),
+	}
+
+	if ring.issuer == "" {
+		ring.issuer = "chatit"
+	}
+	if ring.audience == "" {
+		ring.audience = "chatit"
+	}
+
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" {
+		algorithm = "HS256"
+	}
+
+	var err error
+
+	switch algorithm {
+	case "HS256":
+		ring.signing, err = secretKey(os.Getenv("JWT_SECRET"))
+	case "RS256", "EdDSA":
+		ring.signing, err = privateKeyFile(os.Getenv("JWT_PRIVATE_KEY_FILE"), algorithm)
+	default:
+		err = fmt.Errorf("unsupported JWT_ALGORITHM %q", algorithm)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	ring.verify[ring.signing.id] = ring.signing
+
+	for _, secret := range splitList(os.Getenv("JWT_PREVIOUS_SECRETS")) {
+		key, err := secretKey(secret)
+		if err != nil {
+			return fmt.Errorf("JWT_PREVIOUS_SECRETS: %w", err)
+		}
+		ring.verify[key.id] = key
+	}
+
+	for _, path := range splitList(os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES")) {
+		key, err := publicKeyFile(path)
+		if err != nil {
+			return fmt.Errorf("JWT_PREVIOUS_PUBLIC_KEY_FILES: %w", err)
+		}
+		ring.verify[key.id] = key
+	}
+
+	keys = ring
+
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// the key ID of a secret is derived from it without revealing it
+func secretKey(secret string) (*jwtKey, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("JWT secret must be at least %d bytes", minSecretLength)
+	}
+
+	sum := sha256.Sum256([]byte("kid:" + secret))
+
+	return &jwtKey{
+		id:      "hs-" + hex.EncodeToString(sum[:8]),
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	if path == "" {
+		return nil, errors.New("JWT key file not set")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+
+	return block, nil
+}
+
+func privateKeyFile(path string, algorithm string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var private interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	signer, match := private.(crypto.Signer)
+	if !match {
+		return nil, fmt.Errorf("%s does not hold a signing key", path)
+	}
+
+	key, err := newPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	if key.method.Alg() != algorithm {
+		return nil, fmt.Errorf("%s holds a %s key, not %s", path, key.method.Alg(), algorithm)
+	}
+
+	key.private = private
+
+	return key, nil
+}
+
+func publicKeyFile(path string) (*jwtKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPublicKey(public)
+}
+
+// key IDs of public keys are their RFC 7638 thumbprints, so every instance derives the same one
+func newPublicKey(public interface{}) (*jwtKey, error) {
+	key := &jwtKey{public: public}
+
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("unsupported JWT key type")
+	}
+
+	jwk := publicJWK(key)
+	members := map[string]string{"kty": jwk["kty"]}
+	for _, name := range []string{"crv", "e", "n", "x"} {
+		if value, exists := jwk[name]; exists {
+			members[name] = value
+		}
+	}
+
+	// encoding/json sorts map keys, which is the member order the thumbprint needs
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(canonical)
+	key.id = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return key, nil
+}
+
+func publicJWK(key *jwtKey) map[string]string {
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return map[string]string{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"x":   base64.RawURLEncoding.EncodeToString(public),
+		}
+	}
+
+	return nil
+}
+
+// The public verification keys as a JWK set. HS256 secrets are never published, so the set is
+// empty unless asymmetric keys are in use.
+func JWKS() map[string]interface{} {
+	jwks := []map[string]string{}
+
+	if keys != nil {
+		for _, key := range keys.verify {
+			jwk := publicJWK(key)
+			if jwk == nil {
+				continue
+			}
+			jwk["kid"] = key.id
+			jwk["alg"] = key.method.Alg()
+			jwk["use"] = "sig"
+			jwks = append(jwks, jwk)
+		}
+	}
+
+	return map[string]interface{}{"keys": jwks}
+}
+
+// signs claims with the current key, adding the standard claims every token carries
+func signJWT(claims jwt.MapClaims, lifetime time.Duration) (string, error) {
+	if keys == nil {
+		return "", ErrKeysNotLoaded
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims["iss"] = keys.issuer
+	claims["aud"] = keys.audience
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifetime).Unix()
+	claims["jti"] = hex.EncodeToString(jti)
+
+	token := jwt.NewWithClaims(keys.signing.method, claims)
+	token.Header["kid"] = keys.signing.id
+
+	return token.SignedString(keys.signing.private)
+}
+
+// checks a token against the key named by its kid header, along with its issuer, audience and expiry
+func CheckTokenValidity(tokenStr string) (*jwt.Token, error) {
+	if keys == nil {
+		return nil, ErrKeysNotLoaded
+	}
+
+	return jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, exists := keys.verify[kid]
+		if !exists || token.Method.Alg() != key.method.Alg() {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+
+		return key.public, nil
+	},
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+		jwt.WithIssuer(keys.issuer),
+		jwt.WithAudience(keys.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+}
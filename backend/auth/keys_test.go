@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testSecret      = "an old secret that is long enough to use"
+	testOtherSecret = "a new secret that is also long enough to use"
+)
+
+// writes a fresh Ed25519 key pair as PEM files and returns their paths
+func writeEd25519Keys(t *testing.T) (string, string) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return privatePath, publicPath
+}
+
+// loads keys from the given environment, restoring the previous ring afterwards
+func loadTestKeys(t *testing.T, env map[string]string) error {
+	t.Helper()
+
+	previous := keys
+	t.Cleanup(func() { keys = previous })
+
+	for _, name := range []string{"JWT_ALGORITHM", "JWT_SECRET", "JWT_PRIVATE_KEY_FILE", "JWT_PREVIOUS_SECRETS", "JWT_PREVIOUS_PUBLIC_KEY_FILES", "JWT_ISSUER", "JWT_AUDIENCE"} {
+		t.Setenv(name, env[name])
+	}
+
+	return LoadKeys()
+}
+
+func TestLoadKeysRejectsBadKeys(t *testing.T) {
+	cases := []map[string]string{
+		{"JWT_SECRET": "short"},
+		{"JWT_SECRET": testSecret, "JWT_PREVIOUS_SECRETS": "short"},
+		{"JWT_ALGORITHM": "none"},
+		{"JWT_ALGORITHM": "EdDSA"},
+		{"JWT_ALGORITHM": "RS256", "JWT_PRIVATE_KEY_FILE": "/does/not/exist.pem"},
+	}
+
+	for _, env := range cases {
+		if err := loadTestKeys(t, env); err == nil {
+			t.Errorf("keys loaded from %v", env)
+		}
+	}
+
+	// an Ed25519 key cannot be used as RS256
+	private, _ := writeEd25519Keys(t)
+	if err := loadTestKeys(t, map[string]string{"JWT_ALGORITHM": "RS256", "JWT_PRIVATE_KEY_FILE": private}); err == nil {
+		t.Errorf("Ed25519 key loaded as RS256")
+	}
+}
+
+func TestSecretRotation(t *testing.T) {
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testSecret}); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := GenerateJWT(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testOtherSecret, "JWT_PREVIOUS_SECRETS": testSecret}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckTokenValidity(old); err != nil {
+		t.Fatalf("token from the previous secret rejected: %v", err)
+	}
+
+	// HS256 secrets are never published
+	if jwks := JWKS()["keys"].([]map[string]string); len(jwks) != 0 {
+		t.Fatalf("JWKS published %d secrets", len(jwks))
+	}
+
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testOtherSecret}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckTokenValidity(old); err == nil {
+		t.Fatalf("token from a retired secret accepted")
+	}
+}
+
+func TestEdDSAKeys(t *testing.T) {
+	private, public := writeEd25519Keys(t)
+
+	if err := loadTestKeys(t, map[string]string{"JWT_ALGORITHM": "EdDSA", "JWT_PRIVATE_KEY_FILE": private}); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := GenerateJWT(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := CheckTokenValidity(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := JWKS()["keys"].([]map[string]string)
+	if len(jwks) != 1 || jwks[0]["kid"] != token.Header["kid"] || jwks[0]["alg"] != "EdDSA" {
+		t.Fatalf("JWKS %v does not describe the signing key", jwks)
+	}
+
+	// the public key file derives the same key ID, so tokens survive rotating to a new algorithm
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testSecret, "JWT_PREVIOUS_PUBLIC_KEY_FILES": public}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckTokenValidity(signed); err != nil {
+		t.Fatalf("token from the previous key rejected: %v", err)
+	}
+
+	// an HS256 token keyed with the public key under its kid must not verify
+	publicPEM, err := os.ReadFile(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, token.Claims)
+	forged.Header["kid"] = token.Header["kid"]
+
+	forgedStr, err := forged.SignedString(publicPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckTokenValidity(forgedStr); err == nil {
+		t.Fatalf("HS256 token under an EdDSA kid accepted")
+	}
+}
+
+func TestTokenClaims(t *testing.T) {
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testSecret, "JWT_ISSUER": "one"}); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := GenerateJWT(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loadTestKeys(t, map[string]string{"JWT_SECRET": testSecret, "JWT_ISSUER": "two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckTokenValidity(signed); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Fatalf("token from another issuer accepted: %v", err)
+	}
+}
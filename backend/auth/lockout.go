@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"backend/database"
+	"backend/utils"
+	"database/sql"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maxLoginDelay = 30 * time.Second
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// Returns how long the client has to wait before another login attempt for username is accepted.
+// Each failure on an account doubles the delay before the next attempt, and after LOGIN_MAX_FAILURES
+// (default 5) the account is locked for LOGIN_LOCKOUT (default 15m). An IP failing LOGIN_IP_MAX_FAILURES
+// (default 20) times across any accounts is locked out for the same period.
+func LoginRetryAfter(db *sql.DB, username string, ip string) (time.Duration, error) {
+	maxFailures := envInt("LOGIN_MAX_FAILURES", 5)
+	maxIPFailures := envInt("LOGIN_IP_MAX_FAILURES", 20)
+	lockout := envDuration("LOGIN_LOCKOUT", 15*time.Minute)
+
+	since := time.Now().Add(-lockout)
+	var wait time.Duration
+
+	failures, last, err := database.ReadAccountLoginFailures(db, username, since)
+	if err != nil {
+		return 0, err
+	}
+
+	if last != nil {
+		if failures >= maxFailures {
+			wait = time.Until(last.Add(lockout))
+		} else if failures >= 2 {
+			delay := time.Second << (failures - 2)
+			if delay > maxLoginDelay {
+				delay = maxLoginDelay
+			}
+			wait = time.Until(last.Add(delay))
+		}
+	}
+
+	ipFailures, ipLast, err := database.ReadIPLoginFailures(db, ip, since)
+	if err != nil {
+		return 0, err
+	}
+
+	if ipLast != nil && ipFailures >= maxIPFailures {
+		if ipWait := time.Until(ipLast.Add(lockout)); ipWait > wait {
+			wait = ipWait
+		}
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
+
+	return wait, nil
+}
+
+var dummyHash string
+var dummyHashOnce sync.Once
+
+// spends as long as a real password check, so unknown usernames can't be told apart by response time
+func CheckDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = utils.HashingPassword("dummy password")
+	})
+
+	utils.VerifyPassword(dummyHash, password)
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"backend/database"
+	"backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"testing"
+	"time"
+)
+
+// Connects to the database named by TEST_DATABASE_URL and creates the schema. Tests that need a database
+// are skipped without it; never point it at a database whose data you want to keep.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connection := os.Getenv("TEST_DATABASE_URL")
+	if connection == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("pgx", connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.InitDB(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// a name no other test run has used, since tests share the database
+func testName(prefix string) string {
+	bytes := make([]byte, 6)
+	rand.Read(bytes)
+
+	return prefix + hex.EncodeToString(bytes)
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	db := testDB(t)
+
+	t.Setenv("LOGIN_MAX_FAILURES", "3")
+	t.Setenv("LOGIN_IP_MAX_FAILURES", "4")
+	t.Setenv("LOGIN_LOCKOUT", "1h")
+
+	username := testName("test_")
+	ip := testName("ip-")
+
+	attempt := func(username string, success bool, pending bool) {
+		t.Helper()
+
+		err := database.CreateLoginAttempt(db, &models.LoginAttempt{Username: username, IP: ip, Success: success, Pending: pending})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retryAfter := func(username string) time.Duration {
+		t.Helper()
+
+		wait, err := LoginRetryAfter(db, username, ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return wait
+	}
+
+	attempt(username, false, false)
+	if wait := retryAfter(username); wait != 0 {
+		t.Fatalf("one failure waits %s", wait)
+	}
+
+	// the second failure starts the backoff
+	attempt(username, false, false)
+	if wait := retryAfter(username); wait <= 0 || wait > time.Second {
+		t.Fatalf("two failures wait %s, want up to 1s", wait)
+	}
+
+	// a password accepted before the second factor is neither a failure nor a success
+	attempt(username, false, true)
+	if wait := retryAfter(username); wait > time.Second {
+		t.Fatalf("a pending login counted as a failure")
+	}
+
+	attempt(username, false, false)
+	if wait := retryAfter(username); wait < 59*time.Minute {
+		t.Fatalf("three failures wait %s, want the lockout", wait)
+	}
+
+	// a successful login clears the account's failures
+	attempt(username, true, false)
+	if wait := retryAfter(username); wait != 0 {
+		t.Fatalf("login after success waits %s", wait)
+	}
+
+	// the IP has failed three times, once more on any account locks it out
+	other := testName("test_")
+	attempt(other, false, false)
+	if wait := retryAfter(testName("test_")); wait < 59*time.Minute {
+		t.Fatalf("IP failures wait %s, want the lockout", wait)
+	}
+}
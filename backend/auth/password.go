@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// bcrypt ignores anything past 72 bytes, so longer passwords are refused whichever hasher is in use
+const maxPasswordBytes = 72
+
+// a password rejected by the policy, with a reason that can be shown to the user
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+// Checks a new password against the policy: PASSWORD_MIN_LENGTH characters (default 10), no reuse of
+// the username, and not in the breached password list when PASSWORD_BREACHED_DIR is set. Violations
+// come back as *PasswordPolicyError, anything else is a failure to check.
+func CheckPasswordPolicy(password string, username string) error {
+	minLength := envInt("PASSWORD_MIN_LENGTH", 10)
+
+	if utf8.RuneCountInString(password) < minLength {
+		return &PasswordPolicyError{fmt.Sprintf("Password must be at least %d characters", minLength)}
+	}
+
+	if len(password) > maxPasswordBytes {
+		return &PasswordPolicyError{fmt.Sprintf("Password must be at most %d bytes", maxPasswordBytes)}
+	}
+
+	if len(username) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return &PasswordPolicyError{"Password must not contain the username"}
+	}
+
+	breached, err := isBreachedPassword(password)
+
+	if err != nil {
+		return err
+	}
+
+	if breached {
+		return &PasswordPolicyError{"Password has appeared in a data breach, please choose another"}
+	}
+
+	return nil
+}
+
+// Looks the password up in a local copy of the Pwned Passwords range files, laid out as the range API
+// serves them: one <first 5 SHA-1 hex chars>.txt file per prefix holding SUFFIX:COUNT lines. Only the
+// prefix picks the file, so the full hash is never needed outside this process.
+func isBreachedPassword(password string) (bool, error) {
+	dir := os.Getenv("PASSWORD_BREACHED_DIR")
+	if dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(dir, prefix+".txt"))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		if strings.EqualFold(lineSuffix, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// returns a random single-use token to send to the user and the hash to store in its place
+func GenerateToken() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+
+	token := hex.EncodeToString(bytes)
+
+	return token, HashToken(token), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// prefixed so leaked access tokens are easy to recognise and scan for
+const AccessTokenPrefix = "chatit_pat_"
+
+func GenerateAccessToken() (string, string, error) {
+	token, _, err := GenerateToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	token = AccessTokenPrefix + token
+
+	return token, HashToken(token), nil
+}
+
+// the double-submit CSRF token lives in this cookie and must be echoed in this header
+const (
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, truncated to six digits
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range cases {
+		if got := totpCode(key, unix/totpPeriod); got != want {
+			t.Errorf("code at %d is %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := time.Now().Unix() / totpPeriod
+
+	// the previous step is accepted for clock drift
+	step, ok := ValidateTOTP(secret, totpCode(key, current-1), 0)
+	if !ok || step != current-1 {
+		t.Fatalf("previous step rejected")
+	}
+
+	// but a code is never accepted twice
+	if _, ok := ValidateTOTP(secret, totpCode(key, current-1), step); ok {
+		t.Fatalf("replayed code accepted")
+	}
+
+	if _, ok := ValidateTOTP(strings.ToLower(secret), totpCode(key, current), step); !ok {
+		t.Fatalf("lowercase secret rejected")
+	}
+
+	if _, ok := ValidateTOTP(secret, totpCode(key, current+5), 0); ok {
+		t.Fatalf("code far from now accepted")
+	}
+
+	if _, ok := ValidateTOTP(secret, "12345", 0); ok {
+		t.Fatalf("short code accepted")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Fatalf("malformed recovery code %q", code)
+		}
+
+		if seen[code] {
+			t.Fatalf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+
+		// users may retype codes without the dash or in capitals
+		if HashRecoveryCode(code) != HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))+" ") {
+			t.Fatalf("recovery code %q does not normalise", code)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package badges
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// The badges users can earn. Thresholds come from BADGE_LIKES_RECEIVED (default 100),
+// BADGE_MEMBER_DAYS (default 365) and BADGE_POPULAR_VIEWS (default 1000).
+func Definitions() []models.Badge {
+	likes := envInt("BADGE_LIKES_RECEIVED", 100)
+	days := envInt("BADGE_MEMBER_DAYS", 365)
+	views := envInt("BADGE_POPULAR_VIEWS", 1000)
+
+	return []models.Badge{
+		{
+			Code:        models.BadgeFirstPost,
+			Name:        "First post",
+			Description: "Wrote a first post",
+			Threshold:   1,
+		},
+		{
+			Code:        models.BadgeLikesReceived,
+			Name:        "Well liked",
+			Description: fmt.Sprintf("Received %d likes on posts and comments", likes),
+			Threshold:   likes,
+		},
+		{
+			Code:        models.BadgeMemberYear,
+			Name:        "Regular",
+			Description: fmt.Sprintf("Member for %d days", days),
+			Threshold:   days,
+		},
+		{
+			Code:        models.BadgePopularPost,
+			Name:        "Popular post",
+			Description: fmt.Sprintf("Wrote a post with %d views", views),
+			Threshold:   views,
+		},
+	}
+}
+
+// fills in the name and description of awarded badges from their definitions
+func Describe(userBadges []models.UserBadge) {
+	definitions := map[string]models.Badge{}
+	for _, badge := range Definitions() {
+		definitions[badge.Code] = badge
+	}
+
+	for i := range userBadges {
+		badge := definitions[userBadges[i].Code]
+		userBadges[i].Name = badge.Name
+		userBadges[i].Description = badge.Description
+	}
+}
+
+// Starts the background worker, which checks every badge rule against the whole forum when the server
+// starts and then every BADGE_INTERVAL (default 1h). Awards are idempotent, so nothing is handed out twice.
+func Start(db *sql.DB) {
+	interval := time.Hour
+	if value, err := time.ParseDuration(os.Getenv("BADGE_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+
+	go work(db, interval)
+}
+
+func work(db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, badge := range Definitions() {
+			count, err := database.AwardBadge(db, badge)
+
+			if err != nil {
+				log.Println("badge", badge.Code, "failed:", err)
+				continue
+			}
+
+			if count > 0 {
+				log.Println("awarded badge", badge.Code, "to", count, "users")
+			}
+		}
+
+		<-ticker.C
+	}
+}
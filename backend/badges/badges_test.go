@@ -0,0 +1,45 @@
+package badges
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestDefinitions(t *testing.T) {
+	t.Setenv("BADGE_LIKES_RECEIVED", "7")
+	t.Setenv("BADGE_MEMBER_DAYS", "not a number")
+	t.Setenv("BADGE_POPULAR_VIEWS", "")
+
+	thresholds := map[string]int{}
+	for _, badge := range Definitions() {
+		thresholds[badge.Code] = badge.Threshold
+	}
+
+	want := map[string]int{
+		models.BadgeFirstPost:     1,
+		models.BadgeLikesReceived: 7,
+		models.BadgeMemberYear:    365,
+		models.BadgePopularPost:   1000,
+	}
+
+	for code, threshold := range want {
+		if thresholds[code] != threshold {
+			t.Errorf("%s threshold is %d, want %d", code, thresholds[code], threshold)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	t.Setenv("BADGE_LIKES_RECEIVED", "7")
+
+	userBadges := []models.UserBadge{{Code: models.BadgeLikesReceived}, {Code: "retired"}}
+	Describe(userBadges)
+
+	if userBadges[0].Name != "Well liked" || userBadges[0].Description != "Received 7 likes on posts and comments" {
+		t.Errorf("described as %+v", userBadges[0])
+	}
+
+	if userBadges[1].Name != "" {
+		t.Errorf("unknown badge described as %+v", userBadges[1])
+	}
+}
@@ -0,0 +1,33 @@
+// Command mockoidc runs the mock OpenID Connect provider from backend/oidc/mock for trying the OIDC
+// login locally. It approves every authorization request straight away.
+//
+//	go run ./cmd/mockoidc -addr localhost:9000
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9000
+//	OIDC_MOCK_CLIENT_ID=chatit
+//	OIDC_MOCK_REDIRECT_URL=http://localhost:3000/oidc/callback
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"backend/oidc/mock"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9000", "address to listen on")
+	flag.Parse()
+
+	issuer := "http://" + *addr
+
+	handler, err := mock.NewHandler(issuer)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("mock OIDC provider listening on " + issuer)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
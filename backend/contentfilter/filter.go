@@ -0,0 +1,126 @@
+package contentfilter
+
+import (
+	"backend/database"
+	"backend/models"
+	"backend/utils"
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// verdicts in increasing order of severity
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	Hold
+	Reject
+	Throttle
+)
+
+// Content is a topic, post or comment about to be published
+type Content struct {
+	Kind   string
+	UserID int64
+	Title  string
+	Body   string
+}
+
+type Result struct {
+	Verdict    Verdict
+	Reason     string
+	RetryAfter time.Duration
+}
+
+type Filter interface {
+	Check(db *sql.DB, content *Content) (Result, error)
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Runs every filter in order. A rejection or throttle stops the pipeline straight away, while a hold
+// is remembered so that a later rejection still takes precedence. Moderators and admins are never filtered.
+func (p *Pipeline) Check(db *sql.DB, content *Content) (Result, error) {
+	role, err := database.ReadUserRoleByID(db, content.UserID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if role == models.RoleModerator || role == models.RoleAdmin {
+		return Result{Verdict: Allow}, nil
+	}
+
+	held := Result{Verdict: Allow}
+
+	for _, filter := range p.filters {
+		result, err := filter.Check(db, content)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if result.Verdict >= Reject {
+			return result, nil
+		}
+
+		if result.Verdict == Hold && held.Verdict == Allow {
+			held = result
+		}
+	}
+
+	return held, nil
+}
+
+var defaultPipeline *Pipeline
+var defaultPipelineOnce sync.Once
+
+// Checks content against the pipeline configured from the environment, built on first use
+func Check(db *sql.DB, content *Content) (Result, error) {
+	defaultPipelineOnce.Do(func() {
+		defaultPipeline = newDefaultPipeline()
+	})
+
+	return defaultPipeline.Check(db, content)
+}
+
+// Defaults can be overridden with:
+//
+//	CONTENT_RATE_LIMIT            per-user, per-kind posting rate, e.g. "5/1m"
+//	CONTENT_DUPLICATE_WINDOW      how far back identical content is rejected, e.g. "24h"
+//	CONTENT_NEW_ACCOUNT_AGE       how long an account counts as new, e.g. "72h"
+//	CONTENT_NEW_ACCOUNT_MAX_LINKS links a new account may post before being held for review
+func newDefaultPipeline() *Pipeline {
+	rateLimit := RateLimit{Limit: 5, Window: time.Minute}
+	if rate := os.Getenv("CONTENT_RATE_LIMIT"); rate != "" {
+		limit, window, err := utils.ParseRate(rate)
+		if err != nil {
+			log.Println("CONTENT_RATE_LIMIT:", err)
+		} else {
+			rateLimit.Limit, rateLimit.Window = limit, window
+		}
+	}
+
+	duplicate := Duplicate{Window: 24 * time.Hour}
+	if window, err := time.ParseDuration(os.Getenv("CONTENT_DUPLICATE_WINDOW")); err == nil && window > 0 {
+		duplicate.Window = window
+	}
+
+	linkLimit := LinkLimit{MaxLinks: 2, NewAccountAge: 72 * time.Hour}
+	if age, err := time.ParseDuration(os.Getenv("CONTENT_NEW_ACCOUNT_AGE")); err == nil && age >= 0 {
+		linkLimit.NewAccountAge = age
+	}
+	if maxLinks, err := strconv.Atoi(os.Getenv("CONTENT_NEW_ACCOUNT_MAX_LINKS")); err == nil && maxLinks >= 0 {
+		linkLimit.MaxLinks = maxLinks
+	}
+
+	return NewPipeline(rateLimit, duplicate, linkLimit, Blocklist{})
+}
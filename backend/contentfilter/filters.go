@@ -0,0 +1,136 @@
+package contentfilter
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RateLimit throttles users creating more than Limit pieces of one kind of content within Window
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (f RateLimit) Check(db *sql.DB, content *Content) (Result, error) {
+	count, oldest, err := database.CountContentByUserIDSince(db, content.Kind, content.UserID, time.Now().Add(-f.Window))
+	if err != nil {
+		return Result{}, err
+	}
+
+	if count < f.Limit {
+		return Result{Verdict: Allow}, nil
+	}
+
+	// the user may post again once the oldest item in the window has aged out
+	retryAfter := f.Window
+	if oldest != nil {
+		retryAfter = time.Until(oldest.Add(f.Window))
+	}
+
+	return Result{
+		Verdict:    Throttle,
+		Reason:     "You are posting too fast",
+		RetryAfter: retryAfter,
+	}, nil
+}
+
+// Duplicate rejects content whose body matches something the same user created within Window
+type Duplicate struct {
+	Window time.Duration
+}
+
+func (f Duplicate) Check(db *sql.DB, content *Content) (Result, error) {
+	duplicate, err := database.IsDuplicateContent(db, content.Kind, content.UserID, content.Body, time.Now().Add(-f.Window))
+	if err != nil {
+		return Result{}, err
+	}
+
+	if duplicate {
+		return Result{Verdict: Reject, Reason: "Duplicate content"}, nil
+	}
+
+	return Result{Verdict: Allow}, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// LinkLimit holds content from accounts younger than NewAccountAge that contains more than MaxLinks links
+type LinkLimit struct {
+	MaxLinks      int
+	NewAccountAge time.Duration
+}
+
+func (f LinkLimit) Check(db *sql.DB, content *Content) (Result, error) {
+	links := len(linkPattern.FindAllStringIndex(content.Title+" "+content.Body, -1))
+	if links <= f.MaxLinks {
+		return Result{Verdict: Allow}, nil
+	}
+
+	user, err := database.ReadUserByID(db, content.UserID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if user == nil || time.Since(user.CreatedAt) < f.NewAccountAge {
+		return Result{Verdict: Hold, Reason: "Too many links from a new account"}, nil
+	}
+
+	return Result{Verdict: Allow}, nil
+}
+
+// Blocklist matches content against the blocked terms managed by moderators. Plain terms match whole
+// words case-insensitively, regex terms are used as written.
+type Blocklist struct{}
+
+func (f Blocklist) Check(db *sql.DB, content *Content) (Result, error) {
+	terms, err := database.ReadBlockedTerm(db, 0, 0)
+	if err != nil {
+		return Result{}, err
+	}
+
+	text := content.Title + " " + content.Body
+	result := Result{Verdict: Allow}
+
+	for _, term := range terms {
+		pattern := `(?i)\b` + regexp.QuoteMeta(term.Pattern) + `\b`
+		if term.IsRegex {
+			pattern = "(?i)" + term.Pattern
+		}
+
+		matcher, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+
+		if !matcher.MatchString(text) {
+			continue
+		}
+
+		if term.Action == models.BlockedTermActionReject {
+			return Result{Verdict: Reject, Reason: "Content contains a blocked term"}, nil
+		}
+
+		result = Result{Verdict: Hold, Reason: "Content contains a flagged term"}
+	}
+
+	return result, nil
+}
+
+// IsValidPattern reports whether a blocked term would compile into a usable matcher
+func IsValidPattern(pattern string, isRegex bool) bool {
+	if strings.TrimSpace(pattern) == "" {
+		return false
+	}
+
+	if !isRegex {
+		return true
+	}
+
+	_, err := regexp.Compile("(?i)" + pattern)
+
+	return err == nil
+}
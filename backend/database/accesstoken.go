@@ -0,0 +1,136 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+func CreateAccessToken(db *sql.DB, token *models.AccessToken) error {
+	token.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_access_tokens (
+		user_id,
+		name,
+		token_hash,
+		scopes,
+		expires_at,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		strings.Join(token.Scopes, " "),
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanAccessToken(scanner interface{ Scan(...interface{}) error }, token *models.AccessToken) error {
+	var scopes string
+
+	if err := scanner.Scan(&token.ID, &token.UserID, &token.Name, &scopes, &token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt); err != nil {
+		return err
+	}
+
+	token.Scopes = strings.Fields(scopes)
+
+	return nil
+}
+
+// returns the unexpired token with this hash, or nil when there is none
+func ReadAccessTokenByHash(db *sql.DB, tokenHash string) (*models.AccessToken, error) {
+	token := models.AccessToken{}
+
+	query := `
+	SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+	FROM users_access_tokens
+	WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > $2)
+	`
+	err := scanAccessToken(db.QueryRow(query, tokenHash, time.Now()), &token)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func ReadAccessTokenByUserID(db *sql.DB, userID int64) ([]models.AccessToken, error) {
+	var tokens []models.AccessToken
+
+	query := `
+	SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+	FROM users_access_tokens
+	WHERE user_id = $1
+	ORDER BY created_at DESC`
+
+	rows, err := db.Query(query, userID)
+
+	if err != nil {
+		return tokens, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var token models.AccessToken
+
+		if err := scanAccessToken(rows, &token); err != nil {
+			return tokens, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return tokens, err
+	}
+
+	return tokens, nil
+}
+
+// only written once a minute per token, so busy bots don't turn every request into a write
+func UpdateAccessTokenLastUsedByID(db *sql.DB, id int64) error {
+	now := time.Now()
+
+	query := `
+	UPDATE users_access_tokens SET last_used_at = $1
+	WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+	_, err := db.Exec(query, now, id, now.Add(-time.Minute))
+
+	return err
+}
+
+func DeleteAccessTokenByID(db *sql.DB, id int64, userID int64) (bool, error) {
+	query := "DELETE FROM users_access_tokens WHERE id = $1 AND user_id = $2"
+	res, err := db.Exec(query, id, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+)
+
+// whether the post belongs to a topic in Q&A mode, false for an unknown post
+func ReadTopicQAModeByPostID(db *sql.DB, postID int64) (bool, error) {
+	var qaMode bool
+
+	query := `
+	SELECT t.qa_mode
+	FROM posts p
+	JOIN topics t ON t.id = p.topic_id
+	WHERE p.id = $1
+	`
+	err := db.QueryRow(query, postID).Scan(&qaMode)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return qaMode, nil
+}
+
+// Sets or, with a nil commentID, clears the post's accepted answer. The author of the accepted comment
+// gains models.AnswerAcceptedPoints and loses them again when the post moves on to another answer;
+// answering your own question earns nothing.
+func UpdateAcceptedAnswerByPostID(db *sql.DB, postID int64, commentID *int64) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var postAuthor int64
+	var previous *int64
+
+	query := "SELECT created_by, accepted_comment_id FROM posts WHERE id = $1 FOR UPDATE"
+	err = tx.QueryRow(query, postID).Scan(&postAuthor, &previous)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if previous != nil && commentID != nil && *previous == *commentID {
+		return false, nil
+	}
+
+	if previous != nil {
+		if err := awardAnswer(tx, *previous, postAuthor, -models.AnswerAcceptedPoints); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE posts SET accepted_comment_id = $1 WHERE id = $2", commentID, postID); err != nil {
+		return false, err
+	}
+
+	if commentID != nil {
+		if err := awardAnswer(tx, *commentID, postAuthor, models.AnswerAcceptedPoints); err != nil {
+			return false, err
+		}
+	}
+
+	return false, tx.Commit()
+}
+
+func awardAnswer(tx *sql.Tx, commentID int64, postAuthor int64, points int) error {
+	var answerAuthor int64
+
+	err := tx.QueryRow("SELECT created_by FROM comments WHERE id = $1", commentID).Scan(&answerAuthor)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if answerAuthor == 0 || answerAuthor == postAuthor {
+		return nil
+	}
+
+	event := models.ReputationEvent{
+		UserID:     answerAuthor,
+		Points:     points,
+		Reason:     models.ReputationAnswerAccepted,
+		SourceType: models.TargetTypeComment,
+		SourceID:   &commentID,
+	}
+
+	return insertReputationEvent(tx, &event, &postAuthor)
+}
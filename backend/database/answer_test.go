@@ -0,0 +1,84 @@
+package database
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestAcceptedAnswer(t *testing.T) {
+	db := testDB(t)
+
+	asker := testUser(t, db)
+	first := testUser(t, db)
+	second := testUser(t, db)
+
+	post := testPost(t, db, asker.ID, nil)
+
+	comment := func(userID int64) int64 {
+		t.Helper()
+
+		comment := models.Comment{Description: "answer", PostID: post.ID, CreatedBy: userID}
+		if err := CreateComment(db, &comment); err != nil {
+			t.Fatal(err)
+		}
+
+		return comment.ID
+	}
+
+	accept := func(commentID *int64) {
+		t.Helper()
+
+		if postNotFound, err := UpdateAcceptedAnswerByPostID(db, post.ID, commentID); err != nil || postNotFound {
+			t.Fatalf("accepting %v: not found %v, err %v", commentID, postNotFound, err)
+		}
+	}
+
+	firstAnswer := comment(first.ID)
+	secondAnswer := comment(second.ID)
+	ownAnswer := comment(asker.ID)
+
+	accept(&firstAnswer)
+	wantReputation(t, db, first.ID, models.AnswerAcceptedPoints)
+
+	// accepting the same answer twice pays once
+	accept(&firstAnswer)
+	wantReputation(t, db, first.ID, models.AnswerAcceptedPoints)
+
+	accept(&secondAnswer)
+	wantReputation(t, db, first.ID, 0)
+	wantReputation(t, db, second.ID, models.AnswerAcceptedPoints)
+
+	// answering your own question earns nothing
+	accept(&ownAnswer)
+	wantReputation(t, db, second.ID, 0)
+	wantReputation(t, db, asker.ID, 0)
+
+	accept(nil)
+
+	readPost, err := ReadPostByID(db, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readPost.AcceptedCommentID != nil || readPost.IsAnswered {
+		t.Fatalf("post %+v still answered after clearing", readPost)
+	}
+
+	// deleting the accepted answer takes its points back and leaves the post unanswered
+	accept(&firstAnswer)
+	if _, err := DeleteCommentByID(db, firstAnswer); err != nil {
+		t.Fatal(err)
+	}
+	wantReputation(t, db, first.ID, 0)
+
+	readPost, err = ReadPostByID(db, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readPost.AcceptedCommentID != nil {
+		t.Fatalf("post still accepts deleted comment %d", *readPost.AcceptedCommentID)
+	}
+
+	if postNotFound, err := UpdateAcceptedAnswerByPostID(db, -1, &secondAnswer); err != nil || !postNotFound {
+		t.Fatalf("unknown post: not found %v, err %v", postNotFound, err)
+	}
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func CreateAuditEntry(db *sql.DB, entry *models.AuditEntry) error {
+	entry.CreatedAt = time.Now()
+
+	var before, after interface{}
+	if entry.Before != nil {
+		before = string(entry.Before)
+	}
+	if entry.After != nil {
+		after = string(entry.After)
+	}
+
+	query := `
+	INSERT INTO audit_log (
+		actor_id,
+		action,
+		target_type,
+		target_id,
+		before,
+		after,
+		request_id,
+		ip,
+		status,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7, $8, $9, $10)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		before,
+		after,
+		entry.RequestID,
+		entry.IP,
+		entry.Status,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func auditFilterCondition(filter *models.AuditFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	counter := 1
+
+	if filter.ActorID != 0 {
+		conditions = append(conditions, "actor_id = $"+strconv.Itoa(counter))
+		args = append(args, filter.ActorID)
+		counter += 1
+	}
+
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = $"+strconv.Itoa(counter))
+		args = append(args, filter.TargetType)
+		counter += 1
+	}
+
+	if filter.TargetID != 0 {
+		conditions = append(conditions, "target_id = $"+strconv.Itoa(counter))
+		args = append(args, filter.TargetID)
+		counter += 1
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= $"+strconv.Itoa(counter))
+		args = append(args, *filter.From)
+		counter += 1
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < $"+strconv.Itoa(counter))
+		args = append(args, *filter.To)
+		counter += 1
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAuditEntry(rows *sql.Rows) (models.AuditEntry, error) {
+	var entry models.AuditEntry
+	var before, after []byte
+
+	err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID, &before, &after, &entry.RequestID, &entry.IP, &entry.Status, &entry.CreatedAt)
+	entry.Before = before
+	entry.After = after
+
+	return entry, err
+}
+
+func ReadAuditLog(db *sql.DB, filter *models.AuditFilter, limit int, offset int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+
+	condition, args := auditFilterCondition(filter)
+	counter := len(args) + 1
+
+	query := `
+	SELECT id, actor_id, action, target_type, target_id, before, after, request_id, ip, status, created_at
+	FROM audit_log` + condition + " ORDER BY id DESC LIMIT $" + strconv.Itoa(counter) + " OFFSET $" + strconv.Itoa(counter+1)
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return entries, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+
+		if err != nil {
+			return entries, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return entries, err
+	}
+
+	return entries, nil
+}
+
+// streams every matching entry, oldest first, to fn without loading the whole log into memory
+func ExportAuditLog(db *sql.DB, filter *models.AuditFilter, fn func(*models.AuditEntry) error) error {
+	condition, args := auditFilterCondition(filter)
+
+	query := `
+	SELECT id, actor_id, action, target_type, target_id, before, after, request_id, ip, status, created_at
+	FROM audit_log` + condition + " ORDER BY id ASC"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"fmt"
+)
+
+// Each rule selects (user_id, source_type, source_id) for every user who qualifies for the badge, with
+// the badge's threshold as $1. Users who already have the badge are left out by the unique constraint.
+var badgeRules = map[string]string{
+	models.BadgeFirstPost: `
+		SELECT created_by, 'post', MIN(id)
+		FROM posts
+		WHERE created_by <> 0
+		GROUP BY created_by
+		HAVING COUNT(*) >= $1`,
+	models.BadgeLikesReceived: `
+		SELECT created_by, '', NULL::INTEGER
+		FROM (
+			SELECT created_by, likes FROM posts
+			UNION ALL
+			SELECT created_by, likes FROM comments
+		) content
+		WHERE created_by <> 0
+		GROUP BY created_by
+		HAVING SUM(likes) >= $1`,
+	models.BadgeMemberYear: `
+		SELECT id, '', NULL::INTEGER
+		FROM users
+		WHERE id <> 0 AND created_at <= NOW() - make_interval(days => $1)`,
+	models.BadgePopularPost: `
+		SELECT created_by, 'post', MIN(id)
+		FROM posts
+		WHERE created_by <> 0 AND views >= $1
+		GROUP BY created_by`,
+}
+
+// Awards the badge to everyone its rule selects who doesn't have it yet, returning how many got it.
+// Running it again awards nothing new, so the worker can evaluate every rule on each pass.
+func AwardBadge(db *sql.DB, badge models.Badge) (int64, error) {
+	rule, ok := badgeRules[badge.Code]
+	if !ok {
+		return 0, fmt.Errorf("no rule for badge %q", badge.Code)
+	}
+
+	query := `
+	INSERT INTO users_badges (user_id, badge, source_type, source_id, awarded_at)
+	SELECT candidate.user_id, $2, candidate.source_type, candidate.source_id, NOW()
+	FROM (` + rule + `) AS candidate(user_id, source_type, source_id)
+	ON CONFLICT (user_id, badge) DO NOTHING`
+
+	res, err := db.Exec(query, badge.Threshold, badge.Code)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count, _ := res.RowsAffected()
+
+	return count, nil
+}
+
+func scanUserBadge(scanner interface{ Scan(...interface{}) error }, userBadge *models.UserBadge) error {
+	return scanner.Scan(&userBadge.UserID, &userBadge.Username, &userBadge.Code, &userBadge.SourceType, &userBadge.SourceID, &userBadge.AwardedAt)
+}
+
+func readUserBadges(db *sql.DB, query string, args ...interface{}) ([]models.UserBadge, error) {
+	userBadges := []models.UserBadge{}
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return userBadges, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userBadge models.UserBadge
+
+		if err := scanUserBadge(rows, &userBadge); err != nil {
+			return userBadges, err
+		}
+
+		userBadges = append(userBadges, userBadge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return userBadges, err
+	}
+
+	return userBadges, nil
+}
+
+func ReadBadgeByUserID(db *sql.DB, userID int64) ([]models.UserBadge, error) {
+	query := `
+	SELECT b.user_id, u.username, b.badge, b.source_type, b.source_id, b.awarded_at
+	FROM users_badges b
+	JOIN users u ON u.id = b.user_id
+	WHERE b.user_id = $1
+	ORDER BY b.awarded_at ASC`
+
+	return readUserBadges(db, query, userID)
+}
+
+// the latest awards across all users, leaving out users who hide their activity
+func ReadRecentBadge(db *sql.DB, limit int, offset int) ([]models.UserBadge, error) {
+	query := `
+	SELECT b.user_id, u.username, b.badge, b.source_type, b.source_id, b.awarded_at
+	FROM users_badges b
+	JOIN users u ON u.id = b.user_id
+	WHERE u.hide_activity = FALSE
+	ORDER BY b.awarded_at DESC, b.id DESC
+	LIMIT $1 OFFSET $2`
+
+	return readUserBadges(db, query, limit, offset)
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestAwardBadge(t *testing.T) {
+	if _, err := AwardBadge(nil, models.Badge{Code: "unknown"}); err == nil {
+		t.Fatalf("badge without a rule awarded")
+	}
+
+	db := testDB(t)
+
+	user := testUser(t, db)
+	first := testPost(t, db, user.ID, nil)
+	testPost(t, db, user.ID, nil)
+
+	badge := models.Badge{Code: models.BadgeFirstPost, Threshold: 1}
+
+	if _, err := AwardBadge(db, badge); err != nil {
+		t.Fatal(err)
+	}
+
+	// awarding again hands out nothing new
+	if count, err := AwardBadge(db, badge); err != nil || count != 0 {
+		t.Fatalf("second pass awarded %d, err %v", count, err)
+	}
+
+	userBadges, err := ReadBadgeByUserID(db, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(userBadges) != 1 || userBadges[0].Code != models.BadgeFirstPost || userBadges[0].SourceID == nil || *userBadges[0].SourceID != first.ID {
+		t.Fatalf("badges %+v, want first_post for post %d", userBadges, first.ID)
+	}
+
+	// nobody this new has been a member for a year
+	if _, err := AwardBadge(db, models.Badge{Code: models.BadgeMemberYear, Threshold: 365}); err != nil {
+		t.Fatal(err)
+	}
+
+	if userBadges, err := ReadBadgeByUserID(db, user.ID); err != nil || len(userBadges) != 1 {
+		t.Fatalf("badges %+v, err %v, want only first_post", userBadges, err)
+	}
+}
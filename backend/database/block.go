@@ -0,0 +1,194 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrDuplicateBlock = errors.New("block already exists")
+var ErrDuplicateMute = errors.New("mute already exists")
+
+// SQL condition matching authors the viewer has muted or blocked.
+// placeholder is the bind parameter holding the viewer ID; a viewer ID of 0 matches nobody.
+func hiddenAuthorCondition(column string, placeholder string) string {
+	return "(" + column + " IN (SELECT muted_id FROM users_mutes WHERE muter_id = " + placeholder + ")" +
+		" OR " + column + " IN (SELECT blocked_id FROM users_blocks WHERE blocker_id = " + placeholder + "))"
+}
+
+func CreateBlock(db *sql.DB, block *models.Block) error {
+	block.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_blocks (
+		blocker_id,
+		blocked_id,
+		created_at
+	)
+	VALUES ($1, $2, $3)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, block.BlockerID, block.BlockedID, block.CreatedAt).Scan(&block.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateBlock
+		}
+		return err
+	}
+
+	return nil
+}
+
+func DeleteBlockByBlockerIDAndBlockedID(db *sql.DB, blockerID int64, blockedID int64) (bool, error) {
+	query := "DELETE FROM users_blocks WHERE blocker_id = $1 AND blocked_id = $2"
+	res, err := db.Exec(query, blockerID, blockedID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func ReadBlockedByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.RelatedUser, error) {
+	query := `
+	SELECT u.id, u.username, b.created_at
+	FROM users_blocks b
+	JOIN users u ON u.id = b.blocked_id
+	WHERE b.blocker_id = $1
+	ORDER BY b.created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	return readRelatedUsers(db, query, userID, limit, offset)
+}
+
+// reports whether blockerID has blocked userID
+func IsBlockedBy(db *sql.DB, blockerID int64, userID int64) (bool, error) {
+	var blocked bool
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM users_blocks WHERE blocker_id = $1 AND blocked_id = $2
+	)
+	`
+	err := db.QueryRow(query, blockerID, userID).Scan(&blocked)
+
+	if err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+// reports whether any user among usernames has blocked userID
+func IsBlockedByAnyUsername(db *sql.DB, usernames []string, userID int64) (bool, error) {
+	var blocked bool
+
+	if len(usernames) == 0 {
+		return false, nil
+	}
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1
+		FROM users_blocks b
+		JOIN users u ON u.id = b.blocker_id
+		WHERE b.blocked_id = $1 AND u.username = ANY($2)
+	)
+	`
+	err := db.QueryRow(query, userID, pq.Array(usernames)).Scan(&blocked)
+
+	if err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+func CreateMute(db *sql.DB, mute *models.Mute) error {
+	mute.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_mutes (
+		muter_id,
+		muted_id,
+		created_at
+	)
+	VALUES ($1, $2, $3)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, mute.MuterID, mute.MutedID, mute.CreatedAt).Scan(&mute.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateMute
+		}
+		return err
+	}
+
+	return nil
+}
+
+func DeleteMuteByMuterIDAndMutedID(db *sql.DB, muterID int64, mutedID int64) (bool, error) {
+	query := "DELETE FROM users_mutes WHERE muter_id = $1 AND muted_id = $2"
+	res, err := db.Exec(query, muterID, mutedID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func ReadMutedByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.RelatedUser, error) {
+	query := `
+	SELECT u.id, u.username, m.created_at
+	FROM users_mutes m
+	JOIN users u ON u.id = m.muted_id
+	WHERE m.muter_id = $1
+	ORDER BY m.created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	return readRelatedUsers(db, query, userID, limit, offset)
+}
+
+func readRelatedUsers(db *sql.DB, query string, userID int64, limit int, offset int) ([]models.RelatedUser, error) {
+	var users []models.RelatedUser
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return users, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.RelatedUser
+
+		if err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt); err != nil {
+			return users, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return users, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestIsBlockedByAnyUsername(t *testing.T) {
+	db := testDB(t)
+
+	author := testUser(t, db)
+	mentioned := testUser(t, db)
+	blocker := testUser(t, db)
+
+	if err := CreateBlock(db, &models.Block{BlockerID: blocker.ID, BlockedID: author.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked, err := IsBlockedByAnyUsername(db, []string{mentioned.Username, "nobody_by_this_name"}, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocked {
+		t.Fatal("mentioning users who did not block the author reported blocked")
+	}
+
+	blocked, err = IsBlockedByAnyUsername(db, []string{mentioned.Username, blocker.Username}, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blocked {
+		t.Fatal("mentioning a user who blocked the author not reported")
+	}
+}
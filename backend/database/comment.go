@@ -109,24 +109,54 @@ func UpdateCommentByID(db *sql.DB, id int64, input *models.UpdateCommentInput) (
 }
 
 // soft deletion of comment by setting description field to empty string
+// Blanks the comment in place. A deleted accepted answer stops being accepted and its author loses the
+// points it earned.
 func DeleteCommentByID(db *sql.DB, id int64) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var postID int64
+
 	query := `
 	UPDATE comments SET
 		description = ''
 	WHERE id = $1
+	RETURNING post_id
 	`
+	err = tx.QueryRow(query, id).Scan(&postID)
 
-	res, err := db.Exec(query, id)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
 
 	if err != nil {
 		return false, err
 	}
 
-	if count, _ := res.RowsAffected(); count == 0 {
-		return true, nil
+	var postAuthor int64
+	var accepted *int64
+
+	query = "SELECT created_by, accepted_comment_id FROM posts WHERE id = $1 FOR UPDATE"
+	err = tx.QueryRow(query, postID).Scan(&postAuthor, &accepted)
+
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
 	}
 
-	return false, nil
+	if accepted != nil && *accepted == id {
+		if err := awardAnswer(tx, id, postAuthor, -models.AnswerAcceptedPoints); err != nil {
+			return false, err
+		}
+
+		if _, err := tx.Exec("UPDATE posts SET accepted_comment_id = NULL WHERE id = $1", postID); err != nil {
+			return false, err
+		}
+	}
+
+	return false, tx.Commit()
 }
 
 func GetCommentOwnerByID(db *sql.DB, commentID int64) (int64, error) {
@@ -143,14 +173,21 @@ func GetCommentOwnerByID(db *sql.DB, commentID int64) (int64, error) {
 	return commentData.CreatedBy, err
 }
 
-func ReadCommentByPostID(db *sql.DB, postID int64, limit int, offset int, sortBy string, order string) ([]models.Comment, error) {
+// Comments by muted or blocked authors are kept in place so threads stay intact, but flagged as collapsed.
+// The accepted answer, if the post has one, always comes first.
+func ReadCommentByPostID(db *sql.DB, postID int64, viewerID int64, limit int, offset int, sortBy string, order string) ([]models.Comment, error) {
 	var comments []models.Comment
 
+	if sortBy == "score" {
+		sortBy = "(likes - dislikes)"
+	}
+
 	query := `
-	SELECT id, description, likes, dislikes, is_edited, post_id, parent_comment_id, created_by, created_at
+	SELECT id, description, likes, dislikes, is_edited, post_id, parent_comment_id, created_by, created_at, ` + hiddenAuthorCondition("created_by", "$4") + `,
+		id IS NOT DISTINCT FROM (SELECT accepted_comment_id FROM posts WHERE posts.id = $1) AS is_accepted
 	FROM comments
 	WHERE post_id = $1 AND parent_comment_id IS NULL
-	ORDER BY ` + sortBy + " " + order + `
+	ORDER BY is_accepted DESC, ` + sortBy + " " + order + `
 	LIMIT $2 OFFSET $3`
 
 	rows, err := db.Query(
@@ -158,6 +195,7 @@ func ReadCommentByPostID(db *sql.DB, postID int64, limit int, offset int, sortBy
 		postID,
 		limit,
 		offset,
+		viewerID,
 	)
 
 	if err != nil {
@@ -169,7 +207,7 @@ func ReadCommentByPostID(db *sql.DB, postID int64, limit int, offset int, sortBy
 	for rows.Next() {
 		var comment models.Comment
 
-		if err := rows.Scan(&comment.ID, &comment.Description, &comment.Likes, &comment.Dislikes, &comment.IsEdited, &comment.PostID, &comment.ParentCommentID, &comment.CreatedBy, &comment.CreatedAt); err != nil {
+		if err := rows.Scan(&comment.ID, &comment.Description, &comment.Likes, &comment.Dislikes, &comment.IsEdited, &comment.PostID, &comment.ParentCommentID, &comment.CreatedBy, &comment.CreatedAt, &comment.IsCollapsed, &comment.IsAccepted); err != nil {
 			return comments, err
 		}
 		username, err := ReadUsernameByID(db, comment.CreatedBy)
@@ -244,11 +282,11 @@ func ReadCommentReactionByByCommentIDAndUserID(db *sql.DB, commentID int64, user
 
 }
 
-func ReadCommentByParentCommentID(db *sql.DB, parentCommentID *int64, limit int, offset int, sortBy string, order string) ([]models.Comment, error) {
+func ReadCommentByParentCommentID(db *sql.DB, parentCommentID *int64, viewerID int64, limit int, offset int, sortBy string, order string) ([]models.Comment, error) {
 	var comments []models.Comment
 
 	query := `
-	SELECT id, description, likes, dislikes, is_edited, post_id, parent_comment_id, created_by, created_at
+	SELECT id, description, likes, dislikes, is_edited, post_id, parent_comment_id, created_by, created_at, ` + hiddenAuthorCondition("created_by", "$4") + `
 	FROM comments
 	WHERE parent_comment_id = $1
 	ORDER BY ` + sortBy + " " + order + `
@@ -259,6 +297,7 @@ func ReadCommentByParentCommentID(db *sql.DB, parentCommentID *int64, limit int,
 		parentCommentID,
 		limit,
 		offset,
+		viewerID,
 	)
 
 	if err != nil {
@@ -270,7 +309,7 @@ func ReadCommentByParentCommentID(db *sql.DB, parentCommentID *int64, limit int,
 	for rows.Next() {
 		var comment models.Comment
 
-		if err := rows.Scan(&comment.ID, &comment.Description, &comment.Likes, &comment.Dislikes, &comment.IsEdited, &comment.PostID, &comment.ParentCommentID, &comment.CreatedBy, &comment.CreatedAt); err != nil {
+		if err := rows.Scan(&comment.ID, &comment.Description, &comment.Likes, &comment.Dislikes, &comment.IsEdited, &comment.PostID, &comment.ParentCommentID, &comment.CreatedBy, &comment.CreatedAt, &comment.IsCollapsed); err != nil {
 			return comments, err
 		}
 
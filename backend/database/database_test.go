@@ -0,0 +1,83 @@
+package database
+
+import (
+	"backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"sync"
+	"testing"
+)
+
+var (
+	testDBOnce sync.Once
+	testDBConn *sql.DB
+	testDBErr  error
+)
+
+// Connects to the database named by TEST_DATABASE_URL and creates the schema once per run. Tests that need
+// a database are skipped without it; never point it at a database whose data you want to keep.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connection := os.Getenv("TEST_DATABASE_URL")
+	if connection == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = sql.Open("pgx", connection)
+		if testDBErr == nil {
+			testDBErr = InitDB(testDBConn)
+		}
+	})
+
+	if testDBErr != nil {
+		t.Fatal(testDBErr)
+	}
+
+	return testDBConn
+}
+
+// a name no other test run has used, since tests share the database
+func testName(prefix string) string {
+	bytes := make([]byte, 6)
+	rand.Read(bytes)
+
+	return prefix + hex.EncodeToString(bytes)
+}
+
+func testUser(t *testing.T, db *sql.DB) models.User {
+	t.Helper()
+
+	user := models.User{
+		Username: testName("test_"),
+		Password: "correct horse battery staple",
+	}
+
+	if err := CreateUser(db, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// creates a topic with one post by the user, carrying the poll when given
+func testPost(t *testing.T, db *sql.DB, userID int64, poll *models.Poll) models.Post {
+	t.Helper()
+
+	topic := models.Topic{Title: "test topic", Description: "test topic", CreatedBy: userID}
+
+	if err := CreateTopic(db, &topic); err != nil {
+		t.Fatal(err)
+	}
+
+	post := models.Post{Title: "test post", Description: "test post", TopicID: topic.ID, CreatedBy: userID, Poll: poll}
+
+	if err := CreatePost(db, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	return post
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func CreateAccountDeletion(db *sql.DB, deletion *models.AccountDeletion) error {
+	deletion.RequestedAt = time.Now()
+
+	query := `
+	INSERT INTO account_deletions (
+		user_id,
+		mode,
+		requested_at,
+		scheduled_for
+	)
+	VALUES ($1, $2, $3, $4)
+	`
+	_, err := db.Exec(query, deletion.UserID, deletion.Mode, deletion.RequestedAt, deletion.ScheduledFor)
+
+	return err
+}
+
+func ReadAccountDeletionByUserID(db *sql.DB, userID int64) (*models.AccountDeletion, error) {
+	deletion := models.AccountDeletion{}
+
+	query := `
+	SELECT user_id, mode, requested_at, scheduled_for
+	FROM account_deletions
+	WHERE user_id = $1
+	`
+	err := db.QueryRow(query, userID).Scan(&deletion.UserID, &deletion.Mode, &deletion.RequestedAt, &deletion.ScheduledFor)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+func DeleteAccountDeletionByUserID(db *sql.DB, userID int64) (bool, error) {
+	query := "DELETE FROM account_deletions WHERE user_id = $1"
+	res, err := db.Exec(query, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Carries out the oldest deletion whose cooling-off period is over, or returns nil when none is due. The
+// row stays locked until the transaction ends, so another server skips it rather than running it twice.
+// The returned paths are the user's export archives, which the caller removes once the rows are gone.
+func ProcessAccountDeletion(db *sql.DB) (*models.AccountDeletion, []string, error) {
+	deletion := models.AccountDeletion{}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+	SELECT user_id, mode, requested_at, scheduled_for
+	FROM account_deletions
+	WHERE scheduled_for <= $1
+	ORDER BY scheduled_for
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+	`
+	err = tx.QueryRow(query, time.Now()).Scan(&deletion.UserID, &deletion.Mode, &deletion.RequestedAt, &deletion.ScheduledFor)
+
+	if err == sql.ErrNoRows {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paths, err := deleteAccount(tx, deletion.UserID, deletion.Mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return &deletion, paths, nil
+}
+
+// collects the first column of every row a statement returns
+func queryIDs(tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	ids := []int64{}
+
+	rows, err := tx.Query(query, args...)
+
+	if err != nil {
+		return ids, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+// Removes a user and everything they own. Reactions are deleted rather than left behind with a NULL
+// user, and the counters of every post and comment they touched are recounted from what remains.
+// Content is handed to the system user 0 in anonymize mode; in delete mode it is removed, except
+// posts other people commented on and comments with replies, which are blanked so the replies keep
+// their thread.
+func deleteAccount(tx *sql.Tx, userID int64, mode string) ([]string, error) {
+	postIDs, err := queryIDs(tx, "DELETE FROM posts_reactions WHERE user_id = $1 RETURNING post_id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	commentIDs, err := queryIDs(tx, "DELETE FROM comments_reactions WHERE user_id = $1 RETURNING comment_id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []string{
+		"UPDATE posts SET created_by = 0 WHERE created_by = $1",
+		"UPDATE comments SET created_by = 0 WHERE created_by = $1",
+		"UPDATE topics SET created_by = 0 WHERE created_by = $1",
+		"UPDATE messages SET sender_id = 0 WHERE sender_id = $1",
+	}
+
+	if mode == models.DeletionDelete {
+		statements = []string{
+			// comment reactions have no cascade, so clear them before their posts go
+			`DELETE FROM comments_reactions WHERE comment_id IN (
+				SELECT c.id FROM comments c
+				JOIN posts p ON p.id = c.post_id
+				WHERE p.created_by = $1
+					AND NOT EXISTS (SELECT 1 FROM comments o WHERE o.post_id = p.id AND o.created_by <> $1)
+			)`,
+			`DELETE FROM posts p
+			WHERE p.created_by = $1
+				AND NOT EXISTS (SELECT 1 FROM comments o WHERE o.post_id = p.id AND o.created_by <> $1)`,
+			"UPDATE posts SET title = '[deleted]', description = '', created_by = 0 WHERE created_by = $1",
+			// replies cascade with their parent, so only comments nobody answered go
+			`DELETE FROM comments_reactions WHERE comment_id IN (
+				SELECT c.id FROM comments c
+				WHERE c.created_by = $1
+					AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_comment_id = c.id)
+			)`,
+			`DELETE FROM comments c
+			WHERE c.created_by = $1
+				AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_comment_id = c.id)`,
+			"UPDATE comments SET description = '', created_by = 0 WHERE created_by = $1",
+			`DELETE FROM topics t
+			WHERE t.created_by = $1
+				AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.topic_id = t.id)`,
+			"UPDATE topics SET created_by = 0 WHERE created_by = $1",
+			"DELETE FROM messages WHERE sender_id = $1",
+		}
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	recountPosts := `
+	UPDATE posts p SET
+		likes = r.likes,
+		dislikes = r.dislikes,
+		popularity = r.likes * 10 - r.dislikes * 5 + p.views
+	FROM (
+		SELECT id,
+			(SELECT COUNT(*) FROM posts_reactions WHERE post_id = posts.id AND reaction) AS likes,
+			(SELECT COUNT(*) FROM posts_reactions WHERE post_id = posts.id AND NOT reaction) AS dislikes
+		FROM posts
+		WHERE id = ANY($1)
+	) r
+	WHERE p.id = r.id
+	`
+	if _, err := tx.Exec(recountPosts, pq.Array(postIDs)); err != nil {
+		return nil, err
+	}
+
+	recountComments := `
+	UPDATE comments c SET
+		likes = (SELECT COUNT(*) FROM comments_reactions WHERE comment_id = c.id AND reaction),
+		dislikes = (SELECT COUNT(*) FROM comments_reactions WHERE comment_id = c.id AND NOT reaction)
+	WHERE c.id = ANY($1)
+	`
+	if _, err := tx.Exec(recountComments, pq.Array(commentIDs)); err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+
+	rows, err := tx.Query("SELECT file_path FROM data_exports WHERE user_id = $1 AND file_path <> ''", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var path string
+
+		if err := rows.Scan(&path); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		paths = append(paths, path)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM users WHERE id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
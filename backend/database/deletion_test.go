@@ -0,0 +1,97 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+// runs due deletions until the user's has been carried out; other tests may have left some behind
+func processDeletionOf(t *testing.T, db *sql.DB, userID int64) {
+	t.Helper()
+
+	for {
+		deletion, _, err := ProcessAccountDeletion(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deletion == nil {
+			t.Fatalf("deletion of user %d was never processed", userID)
+		}
+		if deletion.UserID == userID {
+			return
+		}
+	}
+}
+
+func TestProcessAccountDeletion(t *testing.T) {
+	db := testDB(t)
+
+	leaving := testUser(t, db)
+	staying := testUser(t, db)
+
+	post := testPost(t, db, staying.ID, nil)
+
+	if err := CreatePostReaction(db, &models.PostReaction{PostID: post.ID, UserID: leaving.ID, Reaction: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	answered := models.Comment{Description: "answered", PostID: post.ID, CreatedBy: leaving.ID}
+	if err := CreateComment(db, &answered); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := models.Comment{Description: "reply", PostID: post.ID, ParentCommentID: &answered.ID, CreatedBy: staying.ID}
+	if err := CreateComment(db, &reply); err != nil {
+		t.Fatal(err)
+	}
+
+	unanswered := models.Comment{Description: "unanswered", PostID: post.ID, CreatedBy: leaving.ID}
+	if err := CreateComment(db, &unanswered); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreateCommentReaction(db, &models.CommentReaction{CommentID: reply.ID, UserID: leaving.ID, Reaction: false}); err != nil {
+		t.Fatal(err)
+	}
+
+	deletion := models.AccountDeletion{UserID: leaving.ID, Mode: models.DeletionDelete, ScheduledFor: time.Now().Add(-time.Minute)}
+	if err := CreateAccountDeletion(db, &deletion); err != nil {
+		t.Fatal(err)
+	}
+
+	processDeletionOf(t, db, leaving.ID)
+
+	if user, err := ReadUserByID(db, leaving.ID); err != nil || user != nil {
+		t.Fatalf("user still there: %v, err %v", user, err)
+	}
+
+	readPost, err := ReadPostByID(db, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readPost.Likes != 0 {
+		t.Errorf("post likes %d after the liker left, want 0", readPost.Likes)
+	}
+
+	readReply, err := ReadCommentByID(db, reply.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readReply == nil || readReply.Dislikes != 0 {
+		t.Errorf("reply %+v, want it kept with 0 dislikes", readReply)
+	}
+
+	readAnswered, err := ReadCommentByID(db, answered.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if readAnswered == nil || readAnswered.Description != "" || readAnswered.CreatedBy != 0 {
+		t.Errorf("comment with a reply %+v, want it blanked and handed to user 0", readAnswered)
+	}
+
+	if readUnanswered, err := ReadCommentByID(db, unanswered.ID); err != nil || readUnanswered != nil {
+		t.Errorf("comment without replies %+v, err %v, want it deleted", readUnanswered, err)
+	}
+}
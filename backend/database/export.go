@@ -0,0 +1,234 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"time"
+)
+
+func CreateDataExport(db *sql.DB, export *models.DataExport) error {
+	export.CreatedAt = time.Now()
+	export.Status = models.ExportPending
+
+	query := `
+	INSERT INTO data_exports (
+		user_id,
+		status,
+		created_at
+	)
+	VALUES ($1, $2, $3)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, export.UserID, export.Status, export.CreatedAt).Scan(&export.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanDataExport(scanner interface{ Scan(...interface{}) error }, export *models.DataExport) error {
+	return scanner.Scan(&export.ID, &export.UserID, &export.Status, &export.FilePath, &export.Error, &export.CreatedAt, &export.CompletedAt, &export.ExpiresAt)
+}
+
+func ReadDataExportByID(db *sql.DB, id int64, userID int64) (*models.DataExport, error) {
+	export := models.DataExport{}
+
+	query := `
+	SELECT id, user_id, status, file_path, error, created_at, completed_at, expires_at
+	FROM data_exports
+	WHERE id = $1 AND user_id = $2
+	`
+	err := scanDataExport(db.QueryRow(query, id, userID), &export)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// the user's most recent export that didn't fail, or nil
+func ReadLatestDataExportByUserID(db *sql.DB, userID int64) (*models.DataExport, error) {
+	export := models.DataExport{}
+
+	query := `
+	SELECT id, user_id, status, file_path, error, created_at, completed_at, expires_at
+	FROM data_exports
+	WHERE user_id = $1 AND status <> 'failed'
+	ORDER BY created_at DESC
+	LIMIT 1
+	`
+	err := scanDataExport(db.QueryRow(query, userID), &export)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// Marks the oldest pending export as running and returns it, or nil when none is waiting. Rows locked by
+// another server are skipped, so several instances can work through the queue together.
+func ClaimDataExport(db *sql.DB) (*models.DataExport, error) {
+	export := models.DataExport{}
+
+	query := `
+	UPDATE data_exports SET status = 'running', started_at = $1
+	WHERE id = (
+		SELECT id FROM data_exports
+		WHERE status = 'pending'
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, user_id, status, file_path, error, created_at, completed_at, expires_at
+	`
+	err := scanDataExport(db.QueryRow(query, time.Now()), &export)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// puts exports whose server died part way back in the queue
+func RequeueStaleDataExports(db *sql.DB, startedBefore time.Time) error {
+	query := "UPDATE data_exports SET status = 'pending' WHERE status = 'running' AND started_at < $1"
+	_, err := db.Exec(query, startedBefore)
+
+	return err
+}
+
+func CompleteDataExportByID(db *sql.DB, id int64, filePath string, expiresAt time.Time) error {
+	query := `
+	UPDATE data_exports SET status = 'ready', file_path = $1, completed_at = $2, expires_at = $3
+	WHERE id = $4
+	`
+	_, err := db.Exec(query, filePath, time.Now(), expiresAt, id)
+
+	return err
+}
+
+func FailDataExportByID(db *sql.DB, id int64, reason string) error {
+	query := "UPDATE data_exports SET status = 'failed', error = $1, completed_at = $2 WHERE id = $3"
+	_, err := db.Exec(query, reason, time.Now(), id)
+
+	return err
+}
+
+// removes expired exports, returning their archive paths so the files can be deleted too
+func DeleteExpiredDataExports(db *sql.DB) ([]string, error) {
+	paths := []string{}
+
+	query := `
+	DELETE FROM data_exports
+	WHERE expires_at < $1
+	RETURNING file_path`
+
+	rows, err := db.Query(query, time.Now())
+
+	if err != nil {
+		return paths, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var path string
+
+		if err := rows.Scan(&path); err != nil {
+			return paths, err
+		}
+
+		paths = append(paths, path)
+	}
+
+	if err := rows.Err(); err != nil {
+		return paths, err
+	}
+
+	return paths, nil
+}
+
+func ReadTopicByUserID(db *sql.DB, userID int64) ([]models.Topic, error) {
+	topics := []models.Topic{}
+
+	query := `
+	SELECT id, title, description, created_by, created_at, qa_mode
+	FROM topics
+	WHERE created_by = $1
+	ORDER BY created_at ASC`
+
+	rows, err := db.Query(query, userID)
+
+	if err != nil {
+		return topics, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var topic models.Topic
+
+		if err := rows.Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt, &topic.QAMode); err != nil {
+			return topics, err
+		}
+
+		topics = append(topics, topic)
+	}
+
+	if err := rows.Err(); err != nil {
+		return topics, err
+	}
+
+	return topics, nil
+}
+
+func ReadMessageBySenderID(db *sql.DB, senderID int64) ([]models.Message, error) {
+	messages := []models.Message{}
+
+	query := `
+	SELECT id, conversation_id, sender_id, body, is_edited, created_at
+	FROM messages
+	WHERE sender_id = $1
+	ORDER BY created_at ASC`
+
+	rows, err := db.Query(query, senderID)
+
+	if err != nil {
+		return messages, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var message models.Message
+
+		if err := rows.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Body, &message.IsEdited, &message.CreatedAt); err != nil {
+			return messages, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return messages, err
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,288 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateBlockedTerm = errors.New("blocked term already exists")
+
+// tables holding each kind of filtered content
+var contentTables = map[string]string{
+	models.TargetTypeTopic:   "topics",
+	models.TargetTypePost:    "posts",
+	models.TargetTypeComment: "comments",
+}
+
+// counts the content of a kind the user has created or has pending review since the given time,
+// along with the time of the oldest of them
+func CountContentByUserIDSince(db *sql.DB, kind string, userID int64, since time.Time) (int, *time.Time, error) {
+	var count int
+	var oldest *time.Time
+
+	table, exists := contentTables[kind]
+	if !exists {
+		return 0, nil, nil
+	}
+
+	query := `
+	SELECT COUNT(*), MIN(created_at)
+	FROM (
+		SELECT created_at FROM ` + table + ` WHERE created_by = $1 AND created_at > $2
+		UNION ALL
+		SELECT created_at FROM held_content WHERE user_id = $1 AND created_at > $2 AND kind = $3 AND status = 'pending'
+	) recent
+	`
+	err := db.QueryRow(query, userID, since, kind).Scan(&count, &oldest)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return count, oldest, nil
+}
+
+// reports whether the user already created content of a kind with the same body since the given time
+func IsDuplicateContent(db *sql.DB, kind string, userID int64, body string, since time.Time) (bool, error) {
+	var duplicate bool
+
+	table, exists := contentTables[kind]
+	if !exists {
+		return false, nil
+	}
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM ` + table + `
+		WHERE created_by = $1 AND created_at > $2 AND LOWER(TRIM(description)) = LOWER(TRIM($3))
+	)
+	`
+	err := db.QueryRow(query, userID, since, body).Scan(&duplicate)
+
+	if err != nil {
+		return false, err
+	}
+
+	return duplicate, nil
+}
+
+func CreateBlockedTerm(db *sql.DB, term *models.BlockedTerm) error {
+	term.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO blocked_terms (
+		pattern,
+		is_regex,
+		action,
+		created_by,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, term.Pattern, term.IsRegex, term.Action, term.CreatedBy, term.CreatedAt).Scan(&term.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateBlockedTerm
+		}
+		return err
+	}
+
+	return nil
+}
+
+// a limit of 0 reads every blocked term
+func ReadBlockedTerm(db *sql.DB, limit int, offset int) ([]models.BlockedTerm, error) {
+	var terms []models.BlockedTerm
+	args := []interface{}{}
+
+	query := `
+	SELECT id, pattern, is_regex, action, COALESCE(created_by, 0), created_at
+	FROM blocked_terms
+	ORDER BY created_at DESC`
+
+	if limit > 0 {
+		query = query + " LIMIT $1 OFFSET $2"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return terms, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var term models.BlockedTerm
+
+		if err := rows.Scan(&term.ID, &term.Pattern, &term.IsRegex, &term.Action, &term.CreatedBy, &term.CreatedAt); err != nil {
+			return terms, err
+		}
+
+		terms = append(terms, term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return terms, err
+	}
+
+	return terms, nil
+}
+
+func DeleteBlockedTermByID(db *sql.DB, id int64) (bool, error) {
+	query := "DELETE FROM blocked_terms WHERE id = $1"
+	res, err := db.Exec(query, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func CreateHeldContent(db *sql.DB, held *models.HeldContent) error {
+	held.CreatedAt = time.Now()
+	held.Status = models.HeldStatusPending
+
+	query := `
+	INSERT INTO held_content (
+		kind,
+		user_id,
+		payload,
+		reason,
+		created_at
+	)
+	VALUES ($1, $2, $3::jsonb, $4, $5)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, held.Kind, held.UserID, string(held.Payload), held.Reason, held.CreatedAt).Scan(&held.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanHeldContent(scanner interface{ Scan(...interface{}) error }, held *models.HeldContent) error {
+	var payload string
+
+	err := scanner.Scan(&held.ID, &held.Kind, &held.UserID, &payload, &held.Reason, &held.Status, &held.ReviewedBy, &held.ReviewedAt, &held.CreatedAt)
+	held.Payload = []byte(payload)
+
+	return err
+}
+
+func ReadHeldContentByID(db *sql.DB, id int64) (*models.HeldContent, error) {
+	held := models.HeldContent{}
+
+	query := `
+	SELECT id, kind, user_id, payload::text, reason, status, reviewed_by, reviewed_at, created_at
+	FROM held_content
+	WHERE id = $1
+	`
+	err := scanHeldContent(db.QueryRow(query, id), &held)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &held, nil
+}
+
+// oldest first so the queue is worked through in order
+func ReadHeldContent(db *sql.DB, status string, kind string, limit int, offset int) ([]models.HeldContent, error) {
+	var heldContent []models.HeldContent
+	args := []interface{}{status}
+	counter := 2
+
+	query := `
+	SELECT id, kind, user_id, payload::text, reason, status, reviewed_by, reviewed_at, created_at
+	FROM held_content
+	WHERE status = $1
+	`
+
+	if kind != "" {
+		query = query + " AND kind = $" + strconv.Itoa(counter)
+		args = append(args, kind)
+		counter += 1
+	}
+
+	query = query + " ORDER BY created_at ASC LIMIT $" + strconv.Itoa(counter) + " OFFSET $" + strconv.Itoa(counter+1)
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return heldContent, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var held models.HeldContent
+
+		if err := scanHeldContent(rows, &held); err != nil {
+			return heldContent, err
+		}
+
+		heldContent = append(heldContent, held)
+	}
+
+	if err := rows.Err(); err != nil {
+		return heldContent, err
+	}
+
+	return heldContent, nil
+}
+
+// marks pending held content as reviewed, returning true when it was not pending
+func ReviewHeldContentByID(db *sql.DB, id int64, status string, reviewedBy int64) (bool, error) {
+	query := `
+	UPDATE held_content SET
+		status = $1,
+		reviewed_by = $2,
+		reviewed_at = $3
+	WHERE id = $4 AND status = 'pending'
+	`
+	res, err := db.Exec(query, status, reviewedBy, time.Now(), id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// returns reviewed held content to the queue, used when publishing an approval fails
+func ReopenHeldContentByID(db *sql.DB, id int64) error {
+	query := `
+	UPDATE held_content SET
+		status = 'pending',
+		reviewed_by = NULL,
+		reviewed_at = NULL
+	WHERE id = $1
+	`
+	_, err := db.Exec(query, id)
+
+	return err
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateFollow = errors.New("follow already exists")
+
+func CreateFollow(db *sql.DB, follow *models.Follow) error {
+	follow.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_follows (
+		follower_id,
+		followee_id,
+		created_at
+	)
+	VALUES ($1, $2, $3)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, follow.FollowerID, follow.FolloweeID, follow.CreatedAt).Scan(&follow.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateFollow
+		}
+		return err
+	}
+
+	return nil
+}
+
+func DeleteFollowByFollowerIDAndFolloweeID(db *sql.DB, followerID int64, followeeID int64) (bool, error) {
+	query := "DELETE FROM users_follows WHERE follower_id = $1 AND followee_id = $2"
+	res, err := db.Exec(query, followerID, followeeID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func ReadFollowerByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.FollowUser, error) {
+	query := `
+	SELECT u.id, u.username, f.created_at
+	FROM users_follows f
+	JOIN users u ON u.id = f.follower_id
+	WHERE f.followee_id = $1
+	ORDER BY f.created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	return readFollowUsers(db, query, userID, limit, offset)
+}
+
+func ReadFollowingByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.FollowUser, error) {
+	query := `
+	SELECT u.id, u.username, f.created_at
+	FROM users_follows f
+	JOIN users u ON u.id = f.followee_id
+	WHERE f.follower_id = $1
+	ORDER BY f.created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	return readFollowUsers(db, query, userID, limit, offset)
+}
+
+func readFollowUsers(db *sql.DB, query string, userID int64, limit int, offset int) ([]models.FollowUser, error) {
+	var users []models.FollowUser
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return users, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var user models.FollowUser
+
+		if err := rows.Scan(&user.ID, &user.Username, &user.FollowedAt); err != nil {
+			return users, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return users, err
+	}
+
+	return users, nil
+}
+
+// posts by followed users and from watched topics, minus muted topics, muted posts and muted or blocked authors
+func ReadHomeFeed(db *sql.DB, userID int64, limit int, offset int, sortBy string, order string) ([]models.Post, error) {
+	var posts []models.Post
+
+	query := `
+	SELECT p.id, p.title, p.description, p.topic_id, p.likes, p.dislikes, p.is_edited, p.views, p.popularity, p.created_by, p.created_at, p.accepted_comment_id, p.accepted_comment_id IS NOT NULL
+	FROM posts p
+	WHERE (
+		p.created_by IN (SELECT followee_id FROM users_follows WHERE follower_id = $1)
+		OR p.topic_id IN (SELECT topic_id FROM topics_watches WHERE user_id = $1 AND level IN ('all', 'replies'))
+	)
+	AND p.topic_id NOT IN (SELECT topic_id FROM topics_watches WHERE user_id = $1 AND level = 'muted')
+	AND p.id NOT IN (SELECT post_id FROM posts_watches WHERE user_id = $1 AND level = 'muted')
+	AND NOT ` + hiddenAuthorCondition("p.created_by", "$1") + `
+	ORDER BY p.` + sortBy + " " + order + `
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return posts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var post models.Post
+
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered); err != nil {
+			return posts, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return posts, err
+	}
+
+	return posts, nil
+}
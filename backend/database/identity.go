@@ -0,0 +1,231 @@
+package database
+
+import (
+	"backend/models"
+	"backend/utils"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateIdentity = errors.New("identity already linked")
+
+func CreateOIDCState(db *sql.DB, state *models.OIDCState) error {
+	state.CreatedAt = time.Now()
+
+	// abandoned logins are cleared out as new ones start
+	if _, err := db.Exec("DELETE FROM oidc_states WHERE expires_at < $1", state.CreatedAt); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO oidc_states (
+		state_hash,
+		browser_hash,
+		provider,
+		nonce,
+		code_verifier,
+		link_user_id,
+		expires_at,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+	`
+	_, err := db.Exec(
+		query,
+		state.StateHash,
+		state.BrowserHash,
+		state.Provider,
+		state.Nonce,
+		state.CodeVerifier,
+		state.LinkUserID,
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+
+	return err
+}
+
+// removes and returns an unexpired login state started by the same browser, or nil when there is none,
+// so each state works once
+func ConsumeOIDCState(db *sql.DB, stateHash string, browserHash string, provider string) (*models.OIDCState, error) {
+	state := models.OIDCState{}
+
+	query := `
+	DELETE FROM oidc_states
+	WHERE state_hash = $1 AND browser_hash = $2 AND provider = $3 AND expires_at > $4
+	RETURNING state_hash, browser_hash, provider, nonce, code_verifier, link_user_id, expires_at, created_at
+	`
+	err := db.QueryRow(query, stateHash, browserHash, provider, time.Now()).Scan(&state.StateHash, &state.BrowserHash, &state.Provider, &state.Nonce, &state.CodeVerifier, &state.LinkUserID, &state.ExpiresAt, &state.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+func insertIdentity(tx *sql.Tx, identity *models.Identity) error {
+	identity.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_identities (
+		user_id,
+		provider,
+		subject,
+		email,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id;
+	`
+	err := tx.QueryRow(query, identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt).Scan(&identity.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateIdentity
+		}
+		return err
+	}
+
+	return nil
+}
+
+func CreateIdentity(db *sql.DB, identity *models.Identity) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertIdentity(tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Creates an account for a first OIDC login together with its identity. The password is random, so the
+// account can only sign in through the provider until the user sets a password with a reset link.
+func CreateUserWithIdentity(db *sql.DB, user *models.User, identity *models.Identity) error {
+	hash, hashingErr := utils.HashingPassword(user.Password)
+
+	if hashingErr != nil {
+		return hashingErr
+	}
+
+	user.CreatedAt = time.Now()
+	user.LastActive = user.CreatedAt
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO users (
+		username,
+		password_hash,
+		created_at,
+		last_active,
+		email,
+		email_verified
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+	err = tx.QueryRow(query, user.Username, hash, user.CreatedAt, user.LastActive, user.Email, user.EmailVerified).Scan(&user.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "users_verified_email_idx") {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	identity.UserID = user.ID
+
+	if err := insertIdentity(tx, identity); err != nil {
+		return err
+	}
+
+	user.Password = ""
+
+	return tx.Commit()
+}
+
+func ReadIdentityByProviderSubject(db *sql.DB, provider string, subject string) (*models.Identity, error) {
+	identity := models.Identity{}
+
+	query := `
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM users_identities
+	WHERE provider = $1 AND subject = $2
+	`
+	err := db.QueryRow(query, provider, subject).Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func ReadIdentityByUserID(db *sql.DB, userID int64) ([]models.Identity, error) {
+	var identities []models.Identity
+
+	query := `
+	SELECT id, user_id, provider, subject, email, created_at
+	FROM users_identities
+	WHERE user_id = $1
+	ORDER BY created_at ASC`
+
+	rows, err := db.Query(query, userID)
+
+	if err != nil {
+		return identities, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var identity models.Identity
+
+		if err := rows.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			return identities, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return identities, err
+	}
+
+	return identities, nil
+}
+
+func DeleteIdentityByID(db *sql.DB, id int64, userID int64) (bool, error) {
+	query := "DELETE FROM users_identities WHERE id = $1 AND user_id = $2"
+	res, err := db.Exec(query, id, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
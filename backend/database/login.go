@@ -0,0 +1,121 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"time"
+)
+
+func CreateLoginAttempt(db *sql.DB, attempt *models.LoginAttempt) error {
+	attempt.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO login_attempts (
+		username,
+		user_id,
+		ip,
+		user_agent,
+		success,
+		pending,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		attempt.Username,
+		attempt.UserID,
+		attempt.IP,
+		attempt.UserAgent,
+		attempt.Success,
+		attempt.Pending,
+		attempt.CreatedAt,
+	).Scan(&attempt.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// counts failed logins for a username since the given time that have not been followed by a completed one,
+// along with the time of the latest failure. a password accepted while 2FA is still pending neither counts
+// as a failure nor resets them
+func ReadAccountLoginFailures(db *sql.DB, username string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var last *time.Time
+
+	query := `
+	SELECT COUNT(*), MAX(created_at)
+	FROM login_attempts
+	WHERE username = $1 AND success = FALSE AND pending = FALSE AND created_at > $2
+	AND created_at > COALESCE(
+		(SELECT MAX(created_at) FROM login_attempts WHERE username = $1 AND success = TRUE),
+		'-infinity'
+	)
+	`
+	err := db.QueryRow(query, username, since).Scan(&count, &last)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return count, last, nil
+}
+
+// counts failed logins from an IP since the given time; a success does not reset them, so logging into
+// one account does not clear the way to guess at others
+func ReadIPLoginFailures(db *sql.DB, ip string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var last *time.Time
+
+	query := `
+	SELECT COUNT(*), MAX(created_at)
+	FROM login_attempts
+	WHERE ip = $1 AND success = FALSE AND pending = FALSE AND created_at > $2
+	`
+	err := db.QueryRow(query, ip, since).Scan(&count, &last)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return count, last, nil
+}
+
+func ReadLoginAttemptByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+
+	query := `
+	SELECT id, username, user_id, ip, user_agent, success, pending, created_at
+	FROM login_attempts
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return attempts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var attempt models.LoginAttempt
+
+		if err := rows.Scan(&attempt.ID, &attempt.Username, &attempt.UserID, &attempt.IP, &attempt.UserAgent, &attempt.Success, &attempt.Pending, &attempt.CreatedAt); err != nil {
+			return attempts, err
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return attempts, err
+	}
+
+	return attempts, nil
+}
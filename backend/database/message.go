@@ -0,0 +1,455 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// creates the conversation with the creator and the given users as participants
+func CreateConversation(db *sql.DB, conversation *models.Conversation, participantIDs []int64) error {
+	conversation.CreatedAt = time.Now()
+	conversation.LastMessageAt = conversation.CreatedAt
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO conversations (
+		created_by,
+		created_at,
+		last_message_at
+	)
+	VALUES ($1, $2, $3)
+	RETURNING id;
+	`
+	err = tx.QueryRow(query, conversation.CreatedBy, conversation.CreatedAt, conversation.LastMessageAt).Scan(&conversation.ID)
+
+	if err != nil {
+		return err
+	}
+
+	participantQuery := `
+	INSERT INTO conversations_participants (
+		conversation_id,
+		user_id,
+		joined_at
+	)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (conversation_id, user_id) DO NOTHING;
+	`
+	for _, participantID := range append([]int64{conversation.CreatedBy}, participantIDs...) {
+		if _, err := tx.Exec(participantQuery, conversation.ID, participantID, conversation.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func IsConversationParticipant(db *sql.DB, conversationID int64, userID int64) (bool, error) {
+	var participant bool
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM conversations_participants WHERE conversation_id = $1 AND user_id = $2
+	)
+	`
+	err := db.QueryRow(query, conversationID, userID).Scan(&participant)
+
+	if err != nil {
+		return false, err
+	}
+
+	return participant, nil
+}
+
+// reports whether a block exists in either direction between userID and any of otherIDs
+func IsBlockedBetweenUsers(db *sql.DB, userID int64, otherIDs []int64) (bool, error) {
+	var blocked bool
+
+	if len(otherIDs) == 0 {
+		return false, nil
+	}
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM users_blocks
+		WHERE (blocker_id = $1 AND blocked_id = ANY($2))
+			OR (blocked_id = $1 AND blocker_id = ANY($2))
+	)
+	`
+	err := db.QueryRow(query, userID, pq.Array(otherIDs)).Scan(&blocked)
+
+	if err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+// reports whether a block exists in either direction between userID and another participant of the conversation
+func IsBlockedInConversation(db *sql.DB, conversationID int64, userID int64) (bool, error) {
+	var blocked bool
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1
+		FROM conversations_participants p
+		JOIN users_blocks b ON (b.blocker_id = p.user_id AND b.blocked_id = $2)
+			OR (b.blocked_id = p.user_id AND b.blocker_id = $2)
+		WHERE p.conversation_id = $1 AND p.user_id <> $2
+	)
+	`
+	err := db.QueryRow(query, conversationID, userID).Scan(&blocked)
+
+	if err != nil {
+		return false, err
+	}
+
+	return blocked, nil
+}
+
+func ReadConversationParticipant(db *sql.DB, conversationID int64) ([]models.ConversationParticipant, error) {
+	var participants []models.ConversationParticipant
+
+	query := `
+	SELECT p.user_id, u.username, p.last_read_message_id, p.last_read_at, p.joined_at
+	FROM conversations_participants p
+	JOIN users u ON u.id = p.user_id
+	WHERE p.conversation_id = $1
+	ORDER BY p.joined_at, p.user_id
+	`
+
+	rows, err := db.Query(query, conversationID)
+
+	if err != nil {
+		return participants, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var participant models.ConversationParticipant
+
+		if err := rows.Scan(&participant.UserID, &participant.Username, &participant.LastReadMessageID, &participant.LastReadAt, &participant.JoinedAt); err != nil {
+			return participants, err
+		}
+
+		participants = append(participants, participant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return participants, err
+	}
+
+	return participants, nil
+}
+
+// unread counts exclude the user's own messages and deleted messages
+func ReadConversationByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+
+	query := `
+	SELECT c.id, c.created_by, c.created_at, c.last_message_at,
+		(SELECT COUNT(*) FROM messages m
+			WHERE m.conversation_id = c.id
+			AND m.id > COALESCE(p.last_read_message_id, 0)
+			AND m.sender_id <> $1
+			AND m.body <> '') AS unread_count
+	FROM conversations c
+	JOIN conversations_participants p ON p.conversation_id = c.id AND p.user_id = $1
+	ORDER BY c.last_message_at DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return conversations, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var conversation models.Conversation
+
+		if err := rows.Scan(&conversation.ID, &conversation.CreatedBy, &conversation.CreatedAt, &conversation.LastMessageAt, &conversation.UnreadCount); err != nil {
+			return conversations, err
+		}
+
+		conversations = append(conversations, conversation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return conversations, err
+	}
+
+	for i := range conversations {
+		participants, err := ReadConversationParticipant(db, conversations[i].ID)
+		if err != nil {
+			return conversations, err
+		}
+		conversations[i].Participants = participants
+	}
+
+	return conversations, nil
+}
+
+func ReadConversationByID(db *sql.DB, conversationID int64, userID int64) (*models.Conversation, error) {
+	conversation := models.Conversation{}
+
+	query := `
+	SELECT c.id, c.created_by, c.created_at, c.last_message_at,
+		(SELECT COUNT(*) FROM messages m
+			WHERE m.conversation_id = c.id
+			AND m.id > COALESCE(p.last_read_message_id, 0)
+			AND m.sender_id <> $2
+			AND m.body <> '') AS unread_count
+	FROM conversations c
+	JOIN conversations_participants p ON p.conversation_id = c.id AND p.user_id = $2
+	WHERE c.id = $1
+	`
+	err := db.QueryRow(query, conversationID, userID).Scan(&conversation.ID, &conversation.CreatedBy, &conversation.CreatedAt, &conversation.LastMessageAt, &conversation.UnreadCount)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	participants, err := ReadConversationParticipant(db, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	conversation.Participants = participants
+
+	return &conversation, nil
+}
+
+func ReadUnreadMessageCountByUserID(db *sql.DB, userID int64) (int, error) {
+	var count int
+
+	query := `
+	SELECT COUNT(*)
+	FROM messages m
+	JOIN conversations_participants p ON p.conversation_id = m.conversation_id AND p.user_id = $1
+	WHERE m.id > COALESCE(p.last_read_message_id, 0)
+		AND m.sender_id <> $1
+		AND m.body <> ''
+	`
+	err := db.QueryRow(query, userID).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// sending a message also marks the conversation as read up to it for the sender
+func CreateMessage(db *sql.DB, message *models.Message) error {
+	message.CreatedAt = time.Now()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO messages (
+		conversation_id,
+		sender_id,
+		body,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id;
+	`
+	err = tx.QueryRow(query, message.ConversationID, message.SenderID, message.Body, message.CreatedAt).Scan(&message.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE conversations SET last_message_at = $1 WHERE id = $2", message.CreatedAt, message.ConversationID); err != nil {
+		return err
+	}
+
+	receiptQuery := `
+	UPDATE conversations_participants SET
+		last_read_message_id = $1,
+		last_read_at = $2
+	WHERE conversation_id = $3 AND user_id = $4
+	`
+	if _, err := tx.Exec(receiptQuery, message.ID, message.CreatedAt, message.ConversationID, message.SenderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func ReadMessageByID(db *sql.DB, id int64) (*models.Message, error) {
+	message := models.Message{}
+
+	query := `
+	SELECT id, conversation_id, sender_id, body, is_edited, created_at
+	FROM messages
+	WHERE id = $1
+	`
+	err := db.QueryRow(query, id).Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Body, &message.IsEdited, &message.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// newest messages first; a cursor of 0 starts from the latest message,
+// otherwise only messages older than the cursor message are returned
+func ReadMessageByConversationID(db *sql.DB, conversationID int64, cursor int64, limit int) ([]models.Message, error) {
+	var messages []models.Message
+	args := []interface{}{conversationID}
+	counter := 2
+
+	query := `
+	SELECT id, conversation_id, sender_id, body, is_edited, created_at
+	FROM messages
+	WHERE conversation_id = $1
+	`
+
+	if cursor > 0 {
+		query = query + " AND id < $" + strconv.Itoa(counter)
+		args = append(args, cursor)
+		counter += 1
+	}
+
+	query = query + " ORDER BY id DESC LIMIT $" + strconv.Itoa(counter)
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return messages, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var message models.Message
+
+		if err := rows.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Body, &message.IsEdited, &message.CreatedAt); err != nil {
+			return messages, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return messages, err
+	}
+
+	return messages, nil
+}
+
+func UpdateMessageByID(db *sql.DB, id int64, input *models.UpdateMessageInput) (bool, bool, error) {
+	updates := []string{}
+	args := []interface{}{}
+	counter := 1
+
+	if input.Body != nil {
+		updates = append(updates, "body = $"+strconv.Itoa(counter), "is_edited = 1")
+		args = append(args, *input.Body)
+		counter += 1
+	}
+
+	if len(updates) == 0 {
+		return true, false, nil
+	}
+
+	query := "UPDATE messages SET " + strings.Join(updates, ", ") + " WHERE id = $" + strconv.Itoa(counter)
+	args = append(args, id)
+	res, err := db.Exec(query, args...)
+
+	if err != nil {
+		return false, false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return false, true, nil
+	}
+
+	return false, false, nil
+}
+
+// soft deletion of message by setting body field to empty string
+func DeleteMessageByID(db *sql.DB, id int64) (bool, error) {
+	query := `
+	UPDATE messages SET
+		body = ''
+	WHERE id = $1
+	`
+
+	res, err := db.Exec(query, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func GetMessageOwnerByID(db *sql.DB, messageID int64) (int64, error) {
+	messageData, err := ReadMessageByID(db, messageID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if messageData == nil {
+		return 0, nil
+	}
+
+	return messageData.SenderID, err
+}
+
+// moves the user's read pointer forward to messageID; it never moves backwards.
+// returns true when the message does not belong to the conversation.
+func UpdateReadReceipt(db *sql.DB, conversationID int64, userID int64, messageID int64) (bool, error) {
+	query := `
+	UPDATE conversations_participants SET
+		last_read_message_id = GREATEST(COALESCE(last_read_message_id, 0), $1),
+		last_read_at = $2
+	WHERE conversation_id = $3 AND user_id = $4
+		AND EXISTS (SELECT 1 FROM messages WHERE id = $1 AND conversation_id = $3)
+	`
+	res, err := db.Exec(query, messageID, time.Now(), conversationID, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"backend/models"
+	"testing"
+)
+
+func TestCreateConversation(t *testing.T) {
+	db := testDB(t)
+
+	creator := testUser(t, db)
+	other := testUser(t, db)
+	blocker := testUser(t, db)
+
+	if err := CreateBlock(db, &models.Block{BlockerID: blocker.ID, BlockedID: creator.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked, err := IsBlockedBetweenUsers(db, creator.ID, []int64{other.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocked {
+		t.Fatal("no block between creator and other, but reported blocked")
+	}
+
+	blocked, err = IsBlockedBetweenUsers(db, creator.ID, []int64{other.ID, blocker.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !blocked {
+		t.Fatal("block in the other direction not reported")
+	}
+
+	conversation := models.Conversation{CreatedBy: creator.ID}
+
+	if err := CreateConversation(db, &conversation, []int64{other.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, userID := range []int64{creator.ID, other.ID} {
+		participant, err := IsConversationParticipant(db, conversation.ID, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !participant {
+			t.Fatalf("user %d is not a participant", userID)
+		}
+	}
+
+	participant, err := IsConversationParticipant(db, conversation.ID, blocker.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if participant {
+		t.Fatal("blocker was added to the conversation")
+	}
+}
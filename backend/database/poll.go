@@ -0,0 +1,290 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var ErrDuplicatePollVote = errors.New("user has already voted")
+var ErrInvalidPollOption = errors.New("option does not belong to the poll")
+
+func insertPoll(tx *sql.Tx, poll *models.Poll) error {
+	poll.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO polls (
+		post_id,
+		question,
+		multiple_choice,
+		public_votes,
+		hide_results,
+		closes_at,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id;
+	`
+	err := tx.QueryRow(query, poll.PostID, poll.Question, poll.MultipleChoice, poll.PublicVotes, poll.HideResults, poll.ClosesAt, poll.CreatedAt).Scan(&poll.ID)
+
+	if err != nil {
+		return err
+	}
+
+	for i := range poll.Options {
+		option := &poll.Options[i]
+		option.Position = i + 1
+
+		query := "INSERT INTO polls_options (poll_id, position, text) VALUES ($1, $2, $3) RETURNING id"
+		if err := tx.QueryRow(query, poll.ID, option.Position, option.Text).Scan(&option.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reads the poll on a post with every option's vote count, or nil when the post has none. MyVotes holds
+// the viewer's choices, and for public polls each option lists who picked it.
+func ReadPollByPostID(db *sql.DB, postID int64, viewerID int64) (*models.Poll, error) {
+	poll := models.Poll{Options: []models.PollOption{}, MyVotes: []int64{}}
+
+	query := `
+	SELECT id, post_id, question, multiple_choice, public_votes, hide_results, closes_at, created_at,
+		(SELECT COUNT(*) FROM polls_ballots WHERE poll_id = polls.id)
+	FROM polls
+	WHERE post_id = $1
+	`
+	err := db.QueryRow(query, postID).Scan(&poll.ID, &poll.PostID, &poll.Question, &poll.MultipleChoice, &poll.PublicVotes, &poll.HideResults, &poll.ClosesAt, &poll.CreatedAt, &poll.TotalVoters)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	poll.IsClosed = poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now())
+
+	optionQuery := `
+	SELECT o.id, o.position, o.text, COUNT(v.ballot_id)
+	FROM polls_options o
+	LEFT JOIN polls_votes v ON v.option_id = o.id
+	WHERE o.poll_id = $1
+	GROUP BY o.id
+	ORDER BY o.position`
+
+	rows, err := db.Query(optionQuery, poll.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	positions := map[int64]int{}
+
+	for rows.Next() {
+		var option models.PollOption
+
+		if err := rows.Scan(&option.ID, &option.Position, &option.Text, &option.Votes); err != nil {
+			return nil, err
+		}
+
+		positions[option.ID] = len(poll.Options)
+		poll.Options = append(poll.Options, option)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if viewerID != 0 {
+		poll.MyVotes, err = readPollOptionIDs(db, `
+		SELECT v.option_id
+		FROM polls_votes v
+		JOIN polls_ballots b ON b.id = v.ballot_id
+		WHERE b.poll_id = $1 AND b.user_id = $2
+		ORDER BY v.option_id`, poll.ID, viewerID)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !poll.PublicVotes {
+		return &poll, nil
+	}
+
+	voterQuery := `
+	SELECT v.option_id, u.id, u.username
+	FROM polls_votes v
+	JOIN polls_ballots b ON b.id = v.ballot_id
+	JOIN users u ON u.id = b.user_id
+	WHERE b.poll_id = $1
+	ORDER BY b.created_at`
+
+	voterRows, err := db.Query(voterQuery, poll.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer voterRows.Close()
+
+	for voterRows.Next() {
+		var optionID int64
+		var voter models.PollVoter
+
+		if err := voterRows.Scan(&optionID, &voter.UserID, &voter.Username); err != nil {
+			return nil, err
+		}
+
+		if i, ok := positions[optionID]; ok {
+			poll.Options[i].Voters = append(poll.Options[i].Voters, voter)
+		}
+	}
+
+	if err := voterRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &poll, nil
+}
+
+func readPollOptionIDs(db *sql.DB, query string, args ...interface{}) ([]int64, error) {
+	ids := []int64{}
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return ids, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			return ids, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return ids, err
+	}
+
+	return ids, nil
+}
+
+// records the chosen options on a ballot, failing with ErrInvalidPollOption if any isn't on the poll
+func insertPollVotes(tx *sql.Tx, ballotID int64, pollID int64, optionIDs []int64) error {
+	query := `
+	INSERT INTO polls_votes (ballot_id, option_id)
+	SELECT $1, id FROM polls_options
+	WHERE poll_id = $2 AND id = ANY($3)
+	`
+	res, err := tx.Exec(query, ballotID, pollID, pq.Array(optionIDs))
+
+	if err != nil {
+		return err
+	}
+
+	if count, _ := res.RowsAffected(); count != int64(len(optionIDs)) {
+		return ErrInvalidPollOption
+	}
+
+	return nil
+}
+
+// option IDs must be distinct
+func CreatePollVote(db *sql.DB, pollID int64, userID int64, optionIDs []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ballotID int64
+	now := time.Now()
+
+	query := `
+	INSERT INTO polls_ballots (
+		poll_id,
+		user_id,
+		created_at,
+		updated_at
+	)
+	VALUES ($1, $2, $3, $3)
+	RETURNING id;
+	`
+	err = tx.QueryRow(query, pollID, userID, now).Scan(&ballotID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicatePollVote
+		}
+		return err
+	}
+
+	if err := insertPollVotes(tx, ballotID, pollID, optionIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaces the user's choices, option IDs must be distinct
+func UpdatePollVote(db *sql.DB, pollID int64, userID int64, optionIDs []int64) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var ballotID int64
+
+	query := "UPDATE polls_ballots SET updated_at = $1 WHERE poll_id = $2 AND user_id = $3 RETURNING id"
+	err = tx.QueryRow(query, time.Now(), pollID, userID).Scan(&ballotID)
+
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM polls_votes WHERE ballot_id = $1", ballotID); err != nil {
+		return false, err
+	}
+
+	if err := insertPollVotes(tx, ballotID, pollID, optionIDs); err != nil {
+		return false, err
+	}
+
+	return false, tx.Commit()
+}
+
+func DeletePollVote(db *sql.DB, pollID int64, userID int64) (bool, error) {
+	query := "DELETE FROM polls_ballots WHERE poll_id = $1 AND user_id = $2"
+	res, err := db.Exec(query, pollID, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
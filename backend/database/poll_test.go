@@ -0,0 +1,64 @@
+package database
+
+import (
+	"backend/models"
+	"errors"
+	"testing"
+)
+
+func TestPollVote(t *testing.T) {
+	db := testDB(t)
+
+	author := testUser(t, db)
+	voter := testUser(t, db)
+
+	poll := models.Poll{
+		Question:       "which?",
+		MultipleChoice: true,
+		Options:        []models.PollOption{{Text: "a"}, {Text: "b"}, {Text: "c"}},
+	}
+	post := testPost(t, db, author.ID, &poll)
+
+	a, b, c := poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID
+
+	if err := CreatePollVote(db, poll.ID, voter.ID, []int64{a, b}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreatePollVote(db, poll.ID, voter.ID, []int64{c}); !errors.Is(err, ErrDuplicatePollVote) {
+		t.Fatalf("second ballot: got %v, want ErrDuplicatePollVote", err)
+	}
+
+	ballotNotFound, err := UpdatePollVote(db, poll.ID, voter.ID, []int64{c})
+	if err != nil || ballotNotFound {
+		t.Fatalf("changing the vote: not found %v, err %v", ballotNotFound, err)
+	}
+
+	read, err := ReadPollByPostID(db, post.ID, voter.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.TotalVoters != 1 {
+		t.Errorf("total voters %d, want 1", read.TotalVoters)
+	}
+
+	if len(read.MyVotes) != 1 || read.MyVotes[0] != c {
+		t.Errorf("my votes %v, want [%d]", read.MyVotes, c)
+	}
+
+	want := map[int64]int{a: 0, b: 0, c: 1}
+	for _, option := range read.Options {
+		if option.Votes != want[option.ID] {
+			t.Errorf("option %q has %d votes, want %d", option.Text, option.Votes, want[option.ID])
+		}
+	}
+
+	// an option from another poll is refused and leaves the ballot as it was
+	other := models.Poll{Question: "other?", Options: []models.PollOption{{Text: "x"}, {Text: "y"}}}
+	testPost(t, db, author.ID, &other)
+
+	if _, err := UpdatePollVote(db, poll.ID, voter.ID, []int64{other.Options[0].ID}); !errors.Is(err, ErrInvalidPollOption) {
+		t.Fatalf("foreign option: got %v, want ErrInvalidPollOption", err)
+	}
+}
@@ -10,10 +10,17 @@ import (
 	"time"
 )
 
+// creates the post together with its poll, if it has one
 func CreatePost(db *sql.DB, post *models.Post) error {
 
 	post.CreatedAt = time.Now()
 
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 	INSERT INTO posts (
 		title,
@@ -25,7 +32,7 @@ func CreatePost(db *sql.DB, post *models.Post) error {
 	VALUES ($1, $2, $3, $4, $5)
 	RETURNING id;
 	`
-	err := db.QueryRow(
+	err = tx.QueryRow(
 		query,
 		post.Title,
 		post.Description,
@@ -38,18 +45,26 @@ func CreatePost(db *sql.DB, post *models.Post) error {
 		return err
 	}
 
-	return nil
+	if post.Poll != nil {
+		post.Poll.PostID = post.ID
+
+		if err := insertPoll(tx, post.Poll); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func ReadPostByID(db *sql.DB, id int64) (*models.Post, error) {
 	post := models.Post{}
 
 	query := `
-	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at
+	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at, accepted_comment_id, accepted_comment_id IS NOT NULL
 	FROM posts
 	WHERE id = $1
 	`
-	err := db.QueryRow(query, id).Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt)
+	err := db.QueryRow(query, id).Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -178,21 +193,28 @@ func GetPostOwnerByID(db *sql.DB, postID int64) (int64, error) {
 	return postData.CreatedBy, err
 }
 
-func ReadPostByTopicID(db *sql.DB, topicID int64, limit int, offset int, sortBy string, order string) ([]models.Post, error) {
+// with unanswered set, only posts without an accepted answer are listed
+func ReadPostByTopicID(db *sql.DB, topicID int64, viewerID int64, limit int, offset int, sortBy string, order string, unanswered bool) ([]models.Post, error) {
 	var posts []models.Post
 
 	query := `
-	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at 
+	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at, accepted_comment_id, accepted_comment_id IS NOT NULL
 	FROM posts
-	WHERE topic_id = $1
-	ORDER BY ` + sortBy + " " + order + `
-	LIMIT $2 OFFSET $3`
+	WHERE topic_id = $1 AND NOT ` + hiddenAuthorCondition("created_by", "$4") + `
+	`
+
+	if unanswered {
+		query = query + " AND accepted_comment_id IS NULL"
+	}
+
+	query = query + " ORDER BY " + sortBy + " " + order + " LIMIT $2 OFFSET $3"
 
 	rows, err := db.Query(
 		query,
 		topicID,
 		limit,
 		offset,
+		viewerID,
 	)
 
 	if err != nil {
@@ -204,7 +226,7 @@ func ReadPostByTopicID(db *sql.DB, topicID int64, limit int, offset int, sortBy
 	for rows.Next() {
 		var post models.Post
 
-		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt); err != nil {
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered); err != nil {
 			return posts, err
 		}
 
@@ -218,15 +240,15 @@ func ReadPostByTopicID(db *sql.DB, topicID int64, limit int, offset int, sortBy
 	return posts, nil
 }
 
-func ReadPostBySearchQuery(db *sql.DB, topicID int64, limit int, offset int, sortBy string, order string, searchQuery string) ([]models.Post, error) {
+func ReadPostBySearchQuery(db *sql.DB, topicID int64, viewerID int64, limit int, offset int, sortBy string, order string, searchQuery string) ([]models.Post, error) {
 	var posts []models.Post
-	args := []interface{}{searchQuery}
-	counter := 2
+	args := []interface{}{searchQuery, viewerID}
+	counter := 3
 
 	query := `
-	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at
+	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at, accepted_comment_id, accepted_comment_id IS NOT NULL
 	FROM posts, plainto_tsquery('english', $1) AS query
-	WHERE document @@ query
+	WHERE document @@ query AND NOT ` + hiddenAuthorCondition("created_by", "$2") + `
 	`
 
 	if topicID != 0 {
@@ -253,7 +275,7 @@ func ReadPostBySearchQuery(db *sql.DB, topicID int64, limit int, offset int, sor
 	for rows.Next() {
 		var post models.Post
 
-		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt); err != nil {
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered); err != nil {
 			return posts, err
 		}
 
@@ -325,16 +347,17 @@ func ReadPostReactionByByPostIDAndUserID(db *sql.DB, postID int64, userID int64)
 
 }
 
-func ReadPost(db *sql.DB, limit int, offset int, sortBy string, order string) ([]models.Post, error) {
+func ReadPost(db *sql.DB, viewerID int64, limit int, offset int, sortBy string, order string) ([]models.Post, error) {
 	var posts []models.Post
-	args := []interface{}{}
+	args := []interface{}{viewerID}
 
 	query := `
-	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at
+	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at, accepted_comment_id, accepted_comment_id IS NOT NULL
 	FROM posts
+	WHERE NOT ` + hiddenAuthorCondition("created_by", "$1") + `
 	`
 
-	query = query + " ORDER BY " + sortBy + " " + order + " LIMIT $1 OFFSET $2"
+	query = query + " ORDER BY " + sortBy + " " + order + " LIMIT $2 OFFSET $3"
 	args = append(args, limit, offset)
 
 	rows, err := db.Query(query, args...)
@@ -348,7 +371,7 @@ func ReadPost(db *sql.DB, limit int, offset int, sortBy string, order string) ([
 	for rows.Next() {
 		var post models.Post
 
-		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt); err != nil {
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered); err != nil {
 			return posts, err
 		}
 
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+)
+
+func ReadUserProfileByID(db *sql.DB, id int64) (*models.UserProfile, error) {
+	profile := models.UserProfile{}
+
+	query := `
+	SELECT
+		u.id,
+		u.username,
+		u.display_name,
+		u.bio,
+		u.avatar_url,
+		u.location,
+		u.website,
+		u.created_at,
+		u.hide_activity,
+		u.reputation,
+		(SELECT COUNT(*) FROM posts WHERE created_by = u.id),
+		(SELECT COUNT(*) FROM comments WHERE created_by = u.id),
+		(SELECT COALESCE(SUM(likes), 0) FROM posts WHERE created_by = u.id) +
+		(SELECT COALESCE(SUM(likes), 0) FROM comments WHERE created_by = u.id)
+	FROM users u
+	WHERE u.id = $1
+	`
+	err := db.QueryRow(query, id).Scan(
+		&profile.ID,
+		&profile.Username,
+		&profile.DisplayName,
+		&profile.Bio,
+		&profile.AvatarURL,
+		&profile.Location,
+		&profile.Website,
+		&profile.CreatedAt,
+		&profile.HideActivity,
+		&profile.Reputation,
+		&profile.PostCount,
+		&profile.CommentCount,
+		&profile.LikesReceived,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// the user's latest posts and comments together, newest first, with the start of their text
+func ReadRecentActivityByUserID(db *sql.DB, userID int64, limit int) ([]models.ProfileActivity, error) {
+	activity := []models.ProfileActivity{}
+
+	query := `
+	SELECT 'post', p.id, p.id, p.title, LEFT(p.description, 200), p.created_at
+	FROM posts p
+	WHERE p.created_by = $1
+	UNION ALL
+	SELECT 'comment', c.id, c.post_id, p.title, LEFT(c.description, 200), c.created_at
+	FROM comments c
+	JOIN posts p ON p.id = c.post_id
+	WHERE c.created_by = $1
+	ORDER BY 6 DESC
+	LIMIT $2`
+
+	rows, err := db.Query(query, userID, limit)
+
+	if err != nil {
+		return activity, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.ProfileActivity
+
+		if err := rows.Scan(&item.Type, &item.ID, &item.PostID, &item.PostTitle, &item.Excerpt, &item.CreatedAt); err != nil {
+			return activity, err
+		}
+
+		activity = append(activity, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return activity, err
+	}
+
+	return activity, nil
+}
+
+func ReadPostByUserID(db *sql.DB, userID int64, limit int, offset int, sortBy string, order string) ([]models.Post, error) {
+	var posts []models.Post
+
+	query := `
+	SELECT id, title, description, topic_id, likes, dislikes, is_edited, views, popularity, created_by, created_at, accepted_comment_id, accepted_comment_id IS NOT NULL
+	FROM posts
+	WHERE created_by = $1
+	ORDER BY ` + sortBy + " " + order + `
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return posts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var post models.Post
+
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered); err != nil {
+			return posts, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return posts, err
+	}
+
+	return posts, nil
+}
+
+func ReadCommentByUserID(db *sql.DB, userID int64, limit int, offset int, sortBy string, order string) ([]models.Comment, error) {
+	var comments []models.Comment
+
+	query := `
+	SELECT c.id, c.description, c.likes, c.dislikes, c.is_edited, c.post_id, c.parent_comment_id, c.created_by, c.created_at, u.username
+	FROM comments c
+	JOIN users u ON u.id = c.created_by
+	WHERE c.created_by = $1
+	ORDER BY c.` + sortBy + " " + order + `
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return comments, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment models.Comment
+
+		if err := rows.Scan(&comment.ID, &comment.Description, &comment.Likes, &comment.Dislikes, &comment.IsEdited, &comment.PostID, &comment.ParentCommentID, &comment.CreatedBy, &comment.CreatedAt, &comment.Username); err != nil {
+			return comments, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return comments, err
+	}
+
+	return comments, nil
+}
+
+// the user's reactions to posts and comments together
+func ReadReactionByUserID(db *sql.DB, userID int64, limit int, offset int, order string) ([]models.UserReaction, error) {
+	reactions := []models.UserReaction{}
+
+	query := `
+	SELECT 'post', r.post_id, r.post_id, p.title, r.reaction, r.created_at
+	FROM posts_reactions r
+	JOIN posts p ON p.id = r.post_id
+	WHERE r.user_id = $1
+	UNION ALL
+	SELECT 'comment', r.comment_id, c.post_id, p.title, r.reaction, r.created_at
+	FROM comments_reactions r
+	JOIN comments c ON c.id = r.comment_id
+	JOIN posts p ON p.id = c.post_id
+	WHERE r.user_id = $1
+	ORDER BY 6 ` + order + `
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return reactions, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var reaction models.UserReaction
+
+		if err := rows.Scan(&reaction.TargetType, &reaction.TargetID, &reaction.PostID, &reaction.PostTitle, &reaction.Reaction, &reaction.CreatedAt); err != nil {
+			return reactions, err
+		}
+
+		reactions = append(reactions, reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return reactions, err
+	}
+
+	return reactions, nil
+}
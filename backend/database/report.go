@@ -0,0 +1,207 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateReport = errors.New("report already exists")
+
+func CreateReport(db *sql.DB, report *models.Report) error {
+	report.CreatedAt = time.Now()
+	report.Status = "open"
+
+	query := `
+	INSERT INTO reports (
+		reporter_id,
+		target_type,
+		target_id,
+		reason_code,
+		details,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		report.ReporterID,
+		report.TargetType,
+		report.TargetID,
+		report.ReasonCode,
+		report.Details,
+		report.CreatedAt,
+	).Scan(&report.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateReport
+		}
+		return err
+	}
+
+	return nil
+}
+
+// returns the user responsible for a report target, and whether the target exists
+func ReadReportTargetOwner(db *sql.DB, targetType string, targetID int64) (int64, bool, error) {
+	switch targetType {
+	case models.TargetTypePost:
+		post, err := ReadPostByID(db, targetID)
+		if err != nil || post == nil {
+			return 0, false, err
+		}
+		return post.CreatedBy, true, nil
+	case models.TargetTypeComment:
+		comment, err := ReadCommentByID(db, targetID)
+		if err != nil || comment == nil {
+			return 0, false, err
+		}
+		return comment.CreatedBy, true, nil
+	case models.TargetTypeTopic:
+		topic, err := ReadTopicByID(db, targetID)
+		if err != nil || topic == nil {
+			return 0, false, err
+		}
+		return topic.CreatedBy, true, nil
+	case models.TargetTypeUser:
+		username, err := ReadUsernameByID(db, targetID)
+		if err != nil || username == "" {
+			return 0, false, err
+		}
+		return targetID, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// open reports grouped by target, most reported first
+func ReadOpenReportGroup(db *sql.DB, targetType string, limit int, offset int) ([]models.ReportGroup, error) {
+	var groups []models.ReportGroup
+	args := []interface{}{}
+	counter := 1
+
+	query := `
+	SELECT target_type, target_id, COUNT(*), string_agg(DISTINCT reason_code, ','), MIN(created_at), MAX(created_at)
+	FROM reports
+	WHERE status = 'open'
+	`
+
+	if targetType != "" {
+		query = query + " AND target_type = $" + strconv.Itoa(counter)
+		args = append(args, targetType)
+		counter += 1
+	}
+
+	query = query + " GROUP BY target_type, target_id ORDER BY COUNT(*) DESC, MAX(created_at) DESC LIMIT $" + strconv.Itoa(counter) + " OFFSET $" + strconv.Itoa(counter+1)
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return groups, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var group models.ReportGroup
+		var reasonCodes string
+
+		if err := rows.Scan(&group.TargetType, &group.TargetID, &group.ReportCount, &reasonCodes, &group.FirstReportedAt, &group.LastReportedAt); err != nil {
+			return groups, err
+		}
+		group.ReasonCodes = strings.Split(reasonCodes, ",")
+
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return groups, err
+	}
+
+	return groups, nil
+}
+
+func ReadReportByTarget(db *sql.DB, targetType string, targetID int64, limit int, offset int) ([]models.Report, error) {
+	var reports []models.Report
+
+	query := `
+	SELECT id, reporter_id, target_type, target_id, reason_code, details, status, resolution, resolution_note, resolved_by, resolved_at, created_at
+	FROM reports
+	WHERE target_type = $1 AND target_id = $2
+	ORDER BY created_at DESC
+	LIMIT $3 OFFSET $4`
+
+	rows, err := db.Query(query, targetType, targetID, limit, offset)
+
+	if err != nil {
+		return reports, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var report models.Report
+
+		if err := rows.Scan(&report.ID, &report.ReporterID, &report.TargetType, &report.TargetID, &report.ReasonCode, &report.Details, &report.Status, &report.Resolution, &report.ResolutionNote, &report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt); err != nil {
+			return reports, err
+		}
+
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+// resolves every open report on the target and returns how many were resolved
+func ResolveReportByTarget(db *sql.DB, targetType string, targetID int64, resolution string, note string, resolvedBy int64) (int64, error) {
+	query := `
+	UPDATE reports SET
+		status = 'resolved',
+		resolution = $1,
+		resolution_note = $2,
+		resolved_by = $3,
+		resolved_at = $4
+	WHERE target_type = $5 AND target_id = $6 AND status = 'open'
+	`
+	res, err := db.Exec(query, resolution, note, resolvedBy, time.Now(), targetType, targetID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	count, _ := res.RowsAffected()
+
+	return count, nil
+}
+
+func CreateWarning(db *sql.DB, warning *models.Warning) error {
+	warning.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_warnings (
+		user_id,
+		reason,
+		created_by,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, warning.UserID, warning.Reason, warning.CreatedBy, warning.CreatedAt).Scan(&warning.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
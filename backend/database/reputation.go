@@ -0,0 +1,82 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+)
+
+func CreateReputationEvent(db *sql.DB, event *models.ReputationEvent, createdBy *int64) error {
+	return insertReputationEvent(db, event, createdBy)
+}
+
+// works on the database or inside a transaction
+func insertReputationEvent(q interface {
+	QueryRow(string, ...interface{}) *sql.Row
+}, event *models.ReputationEvent, createdBy *int64) error {
+	query := `
+	INSERT INTO reputation_events (
+		user_id,
+		points,
+		reason,
+		source_type,
+		source_id,
+		note,
+		created_by
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at;
+	`
+	return q.QueryRow(query, event.UserID, event.Points, event.Reason, event.SourceType, event.SourceID, event.Note, createdBy).Scan(&event.ID, &event.CreatedAt)
+}
+
+// the user's total, or 0 for an unknown user
+func ReadReputationByUserID(db *sql.DB, userID int64) (int, error) {
+	var reputation int
+
+	err := db.QueryRow("SELECT reputation FROM users WHERE id = $1", userID).Scan(&reputation)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return reputation, nil
+}
+
+func ReadReputationEventByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.ReputationEvent, error) {
+	events := []models.ReputationEvent{}
+
+	query := `
+	SELECT id, user_id, points, reason, source_type, source_id, note, created_at
+	FROM reputation_events
+	WHERE user_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return events, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.ReputationEvent
+
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Points, &event.Reason, &event.SourceType, &event.SourceID, &event.Note, &event.CreatedAt); err != nil {
+			return events, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return events, err
+	}
+
+	return events, nil
+}
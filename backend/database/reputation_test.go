@@ -0,0 +1,74 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"testing"
+)
+
+func wantReputation(t *testing.T, db *sql.DB, userID int64, want int) {
+	t.Helper()
+
+	reputation, err := ReadReputationByUserID(db, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reputation != want {
+		t.Fatalf("user %d has %d reputation, want %d", userID, reputation, want)
+	}
+}
+
+func TestReactionReputation(t *testing.T) {
+	db := testDB(t)
+
+	author := testUser(t, db)
+	reader := testUser(t, db)
+	moderator := testUser(t, db)
+
+	post := testPost(t, db, author.ID, nil)
+
+	comment := models.Comment{Description: "comment", PostID: post.ID, CreatedBy: author.ID}
+	if err := CreateComment(db, &comment); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreatePostReaction(db, &models.PostReaction{PostID: post.ID, UserID: reader.ID, Reaction: true}); err != nil {
+		t.Fatal(err)
+	}
+	wantReputation(t, db, author.ID, 10)
+
+	if err := CreateCommentReaction(db, &models.CommentReaction{CommentID: comment.ID, UserID: reader.ID, Reaction: false}); err != nil {
+		t.Fatal(err)
+	}
+	wantReputation(t, db, author.ID, 9)
+
+	// taking a reaction back takes its points back
+	if _, err := DeletePostReactionByPostIDAndUserID(db, post.ID, reader.ID); err != nil {
+		t.Fatal(err)
+	}
+	wantReputation(t, db, author.ID, -1)
+
+	penalty := models.ReputationEvent{UserID: author.ID, Points: -20, Reason: models.ReputationPenalty, Note: "spam"}
+	if err := CreateReputationEvent(db, &penalty, &moderator.ID); err != nil {
+		t.Fatal(err)
+	}
+	wantReputation(t, db, author.ID, -21)
+
+	events, err := ReadReputationEventByUserID(db, author.ID, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for _, event := range events {
+		total += event.Points
+	}
+
+	// the ledger always adds up to the total
+	if len(events) != 4 || total != -21 {
+		t.Fatalf("%d events adding up to %d, want 4 adding up to -21", len(events), total)
+	}
+
+	wantReputation(t, db, reader.ID, 0)
+}
@@ -99,6 +99,511 @@ func InitDB(db *sql.DB) error {
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
 		);
 	`
+	topicWatchTable := `
+	CREATE TABLE IF NOT EXISTS topics_watches(
+		id SERIAL PRIMARY KEY,
+		topic_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		level TEXT NOT NULL DEFAULT 'all' CHECK (level IN ('all', 'replies', 'muted')),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(topic_id, user_id),
+		FOREIGN KEY (topic_id) REFERENCES topics(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	postWatchTable := `
+	CREATE TABLE IF NOT EXISTS posts_watches(
+		id SERIAL PRIMARY KEY,
+		post_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		level TEXT NOT NULL DEFAULT 'all' CHECK (level IN ('all', 'replies', 'muted')),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(post_id, user_id),
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userFollowTable := `
+	CREATE TABLE IF NOT EXISTS users_follows(
+		id SERIAL PRIMARY KEY,
+		follower_id INTEGER NOT NULL,
+		followee_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(follower_id, followee_id),
+		CHECK (follower_id <> followee_id),
+		FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userBlockTable := `
+	CREATE TABLE IF NOT EXISTS users_blocks(
+		id SERIAL PRIMARY KEY,
+		blocker_id INTEGER NOT NULL,
+		blocked_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(blocker_id, blocked_id),
+		CHECK (blocker_id <> blocked_id),
+		FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userMuteTable := `
+	CREATE TABLE IF NOT EXISTS users_mutes(
+		id SERIAL PRIMARY KEY,
+		muter_id INTEGER NOT NULL,
+		muted_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(muter_id, muted_id),
+		CHECK (muter_id <> muted_id),
+		FOREIGN KEY (muter_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	conversationTable := `
+	CREATE TABLE IF NOT EXISTS conversations(
+		id SERIAL PRIMARY KEY,
+		created_by INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL,
+		last_message_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET DEFAULT
+		);
+	`
+	conversationParticipantTable := `
+	CREATE TABLE IF NOT EXISTS conversations_participants(
+		id SERIAL PRIMARY KEY,
+		conversation_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		last_read_message_id INTEGER,
+		last_read_at TIMESTAMPTZ,
+		joined_at TIMESTAMPTZ NOT NULL,
+		UNIQUE(conversation_id, user_id),
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	messageTable := `
+	CREATE TABLE IF NOT EXISTS messages(
+		id SERIAL PRIMARY KEY,
+		conversation_id INTEGER NOT NULL,
+		sender_id INTEGER NOT NULL DEFAULT 0,
+		body TEXT NOT NULL,
+		is_edited INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+		FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE SET DEFAULT
+		);
+	`
+	messageIdx := `
+	CREATE INDEX IF NOT EXISTS messages_conversation_id_idx
+	ON messages(conversation_id, id);
+	`
+	userRoleColumn := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'));
+	`
+	reportTable := `
+	CREATE TABLE IF NOT EXISTS reports(
+		id SERIAL PRIMARY KEY,
+		reporter_id INTEGER NOT NULL DEFAULT 0,
+		target_type TEXT NOT NULL CHECK (target_type IN ('post', 'comment', 'topic', 'user')),
+		target_id INTEGER NOT NULL,
+		reason_code TEXT NOT NULL CHECK (reason_code IN ('spam', 'abuse', 'harassment', 'off_topic', 'illegal', 'other')),
+		details TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+		resolution TEXT,
+		resolution_note TEXT,
+		resolved_by INTEGER,
+		resolved_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE SET DEFAULT,
+		FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	reportOpenIdx := `
+	CREATE UNIQUE INDEX IF NOT EXISTS reports_open_idx
+	ON reports(reporter_id, target_type, target_id) WHERE status = 'open' AND reporter_id <> 0;
+	`
+	userWarningTable := `
+	CREATE TABLE IF NOT EXISTS users_warnings(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	userSuspensionTable := `
+	CREATE TABLE IF NOT EXISTS users_suspensions(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		expires_at TIMESTAMPTZ,
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL,
+		lifted_at TIMESTAMPTZ,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	bannedIPTable := `
+	CREATE TABLE IF NOT EXISTS banned_ips(
+		id SERIAL PRIMARY KEY,
+		cidr CIDR NOT NULL UNIQUE,
+		reason TEXT NOT NULL DEFAULT '',
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	bannedUsernameTable := `
+	CREATE TABLE IF NOT EXISTS banned_usernames(
+		id SERIAL PRIMARY KEY,
+		pattern TEXT NOT NULL UNIQUE,
+		reason TEXT NOT NULL DEFAULT '',
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	auditLogTable := `
+	CREATE TABLE IF NOT EXISTS audit_log(
+		id BIGSERIAL PRIMARY KEY,
+		actor_id INTEGER,
+		action TEXT NOT NULL,
+		target_type TEXT,
+		target_id BIGINT,
+		before JSONB,
+		after JSONB,
+		request_id TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL
+		);
+	`
+	auditLogActorIdx := `
+	CREATE INDEX IF NOT EXISTS audit_log_actor_idx
+	ON audit_log(actor_id, created_at);
+	`
+	auditLogTargetIdx := `
+	CREATE INDEX IF NOT EXISTS audit_log_target_idx
+	ON audit_log(target_type, target_id, created_at);
+	`
+	blockedTermTable := `
+	CREATE TABLE IF NOT EXISTS blocked_terms(
+		id SERIAL PRIMARY KEY,
+		pattern TEXT NOT NULL UNIQUE,
+		is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+		action TEXT NOT NULL CHECK (action IN ('hold', 'reject')),
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	heldContentTable := `
+	CREATE TABLE IF NOT EXISTS held_content(
+		id SERIAL PRIMARY KEY,
+		kind TEXT NOT NULL CHECK (kind IN ('topic', 'post', 'comment')),
+		user_id INTEGER NOT NULL,
+		payload JSONB NOT NULL,
+		reason TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+		reviewed_by INTEGER,
+		reviewed_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	heldContentStatusIdx := `
+	CREATE INDEX IF NOT EXISTS held_content_status_idx
+	ON held_content(status, created_at);
+	`
+	loginAttemptTable := `
+	CREATE TABLE IF NOT EXISTS login_attempts(
+		id BIGSERIAL PRIMARY KEY,
+		username TEXT NOT NULL,
+		user_id INTEGER,
+		ip TEXT NOT NULL,
+		user_agent TEXT NOT NULL DEFAULT '',
+		success BOOLEAN NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	loginAttemptUsernameIdx := `
+	CREATE INDEX IF NOT EXISTS login_attempts_username_idx
+	ON login_attempts(username, created_at);
+	`
+	loginAttemptIPIdx := `
+	CREATE INDEX IF NOT EXISTS login_attempts_ip_idx
+	ON login_attempts(ip, created_at);
+	`
+	loginAttemptUserIdx := `
+	CREATE INDEX IF NOT EXISTS login_attempts_user_id_idx
+	ON login_attempts(user_id, created_at);
+	`
+	loginAttemptPendingColumn := `
+	ALTER TABLE login_attempts
+	ADD COLUMN IF NOT EXISTS pending BOOLEAN NOT NULL DEFAULT FALSE;
+	`
+	userTOTPColumns := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS totp_secret TEXT,
+	ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+	`
+	recoveryCodeTable := `
+	CREATE TABLE IF NOT EXISTS users_recovery_codes(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		code_hash TEXT NOT NULL,
+		used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		UNIQUE(user_id, code_hash),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userEmailColumns := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS email TEXT,
+	ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+	`
+	// only a verified address is unique, so nobody can hold an address they haven't proven they own
+	dropUserEmailIdx := `
+	DROP INDEX IF EXISTS users_email_idx;
+	`
+	userEmailIdx := `
+	CREATE UNIQUE INDEX IF NOT EXISTS users_verified_email_idx
+	ON users(LOWER(email)) WHERE email_verified;
+	`
+	userTokenTable := `
+	CREATE TABLE IF NOT EXISTS users_tokens(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		purpose TEXT NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+		token_hash TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userIdentityTable := `
+	CREATE TABLE IF NOT EXISTS users_identities(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		email TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		UNIQUE(provider, subject),
+		UNIQUE(user_id, provider),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	oidcStateTable := `
+	CREATE TABLE IF NOT EXISTS oidc_states(
+		state_hash TEXT PRIMARY KEY,
+		provider TEXT NOT NULL,
+		nonce TEXT NOT NULL,
+		code_verifier TEXT NOT NULL,
+		link_user_id INTEGER,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (link_user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	oidcStateBrowserColumn := `
+	ALTER TABLE oidc_states
+	ADD COLUMN IF NOT EXISTS browser_hash TEXT NOT NULL DEFAULT '';
+	`
+	accessTokenTable := `
+	CREATE TABLE IF NOT EXISTS users_access_tokens(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		scopes TEXT NOT NULL,
+		expires_at TIMESTAMPTZ,
+		last_used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userProfileColumns := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS display_name TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS bio TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS avatar_url TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS location TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS website TEXT NOT NULL DEFAULT '';
+	`
+	postCreatedByIdx := `
+	CREATE INDEX IF NOT EXISTS posts_created_by_idx
+	ON posts(created_by, created_at);
+	`
+	commentCreatedByIdx := `
+	CREATE INDEX IF NOT EXISTS comments_created_by_idx
+	ON comments(created_by, created_at);
+	`
+	userHideActivityColumn := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS hide_activity BOOLEAN NOT NULL DEFAULT FALSE;
+	`
+	postReactionCreatedAtColumn := `
+	ALTER TABLE posts_reactions
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+	`
+	commentReactionCreatedAtColumn := `
+	ALTER TABLE comments_reactions
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+	`
+	postReactionUserIdx := `
+	CREATE INDEX IF NOT EXISTS posts_reactions_user_idx
+	ON posts_reactions(user_id, created_at);
+	`
+	commentReactionUserIdx := `
+	CREATE INDEX IF NOT EXISTS comments_reactions_user_idx
+	ON comments_reactions(user_id, created_at);
+	`
+	dataExportTable := `
+	CREATE TABLE IF NOT EXISTS data_exports(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'ready', 'failed')),
+		file_path TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL,
+		started_at TIMESTAMPTZ,
+		completed_at TIMESTAMPTZ,
+		expires_at TIMESTAMPTZ,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	dataExportStatusIdx := `
+	CREATE INDEX IF NOT EXISTS data_exports_status_idx
+	ON data_exports(status, created_at);
+	`
+	accountDeletionTable := `
+	CREATE TABLE IF NOT EXISTS account_deletions(
+		user_id INTEGER PRIMARY KEY,
+		mode TEXT NOT NULL CHECK (mode IN ('anonymize', 'delete')),
+		requested_at TIMESTAMPTZ NOT NULL,
+		scheduled_for TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	accountDeletionScheduledIdx := `
+	CREATE INDEX IF NOT EXISTS account_deletions_scheduled_for_idx
+	ON account_deletions(scheduled_for);
+	`
+	userReputationColumn := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS reputation INTEGER NOT NULL DEFAULT 0;
+	`
+	reputationEventTable := `
+	CREATE TABLE IF NOT EXISTS reputation_events(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		points INTEGER NOT NULL,
+		reason TEXT NOT NULL CHECK (reason IN ('post_liked', 'post_disliked', 'comment_liked', 'comment_disliked', 'answer_accepted', 'penalty')),
+		source_type TEXT NOT NULL DEFAULT '',
+		source_id INTEGER,
+		note TEXT NOT NULL DEFAULT '',
+		created_by INTEGER,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+		);
+	`
+	reputationEventUserIdx := `
+	CREATE INDEX IF NOT EXISTS reputation_events_user_idx
+	ON reputation_events(user_id, created_at);
+	`
+	reputationEventSourceIdx := `
+	CREATE INDEX IF NOT EXISTS reputation_events_source_idx
+	ON reputation_events(source_type, source_id);
+	`
+	userBadgeTable := `
+	CREATE TABLE IF NOT EXISTS users_badges(
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		badge TEXT NOT NULL,
+		source_type TEXT NOT NULL DEFAULT '',
+		source_id INTEGER,
+		awarded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE(user_id, badge),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	userBadgeAwardedAtIdx := `
+	CREATE INDEX IF NOT EXISTS users_badges_awarded_at_idx
+	ON users_badges(awarded_at);
+	`
+	topicQAModeColumn := `
+	ALTER TABLE topics
+	ADD COLUMN IF NOT EXISTS qa_mode BOOLEAN NOT NULL DEFAULT FALSE;
+	`
+	postAcceptedCommentColumn := `
+	ALTER TABLE posts
+	ADD COLUMN IF NOT EXISTS accepted_comment_id INTEGER REFERENCES comments(id) ON DELETE SET NULL;
+	`
+	pollTable := `
+	CREATE TABLE IF NOT EXISTS polls(
+		id SERIAL PRIMARY KEY,
+		post_id INTEGER NOT NULL UNIQUE,
+		question TEXT NOT NULL,
+		multiple_choice BOOLEAN NOT NULL DEFAULT FALSE,
+		public_votes BOOLEAN NOT NULL DEFAULT FALSE,
+		hide_results BOOLEAN NOT NULL DEFAULT FALSE,
+		closes_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+		);
+	`
+	pollOptionTable := `
+	CREATE TABLE IF NOT EXISTS polls_options(
+		id SERIAL PRIMARY KEY,
+		poll_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		UNIQUE(poll_id, position),
+		FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE
+		);
+	`
+	// a user's ballot holds every option they picked, so one ballot per poll means one vote per user
+	pollBallotTable := `
+	CREATE TABLE IF NOT EXISTS polls_ballots(
+		id SERIAL PRIMARY KEY,
+		poll_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		UNIQUE(poll_id, user_id),
+		FOREIGN KEY (poll_id) REFERENCES polls(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);
+	`
+	pollVoteTable := `
+	CREATE TABLE IF NOT EXISTS polls_votes(
+		ballot_id INTEGER NOT NULL,
+		option_id INTEGER NOT NULL,
+		PRIMARY KEY (ballot_id, option_id),
+		FOREIGN KEY (ballot_id) REFERENCES polls_ballots(id) ON DELETE CASCADE,
+		FOREIGN KEY (option_id) REFERENCES polls_options(id) ON DELETE CASCADE
+		);
+	`
+	pollVoteOptionIdx := `
+	CREATE INDEX IF NOT EXISTS polls_votes_option_idx
+	ON polls_votes(option_id);
+	`
+	userPasswordChangedColumn := `
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+	`
 	/////////////////////////////////////////////////////////////////////////////////////////////////////////
 	FTSTriggerFunction := `
 	CREATE OR REPLACE FUNCTION fts_trigger_handler() RETURNS trigger AS $$
@@ -173,6 +678,109 @@ func InitDB(db *sql.DB) error {
 	LANGUAGE plpgsql
 	`
 
+	auditLogAppendOnlyTriggerFunction := `
+	CREATE OR REPLACE FUNCTION audit_log_append_only_handler() RETURNS trigger as $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$
+	LANGUAGE plpgsql
+	`
+
+	// reactions earn the author of the post or comment points, and removing one takes them back; reacting
+	// to your own content and content owned by the deleted user 0 earns nothing
+	postReactionReputationTriggerFunction := `
+	CREATE OR REPLACE FUNCTION post_reaction_reputation_handler() RETURNS trigger as $$
+	DECLARE
+		target posts_reactions;
+		direction INTEGER := 1;
+		author INTEGER;
+	BEGIN
+		IF TG_OP = 'INSERT' THEN
+			target := new;
+		ELSE
+			target := old;
+			direction := -1;
+		END IF;
+
+		SELECT created_by INTO author FROM posts WHERE id = target.post_id;
+
+		IF author IS NOT NULL AND author <> 0 AND author IS DISTINCT FROM target.user_id THEN
+			INSERT INTO reputation_events (user_id, points, reason, source_type, source_id)
+			VALUES (
+				author,
+				direction * CASE WHEN target.reaction = TRUE THEN 10 ELSE -2 END,
+				CASE WHEN target.reaction = TRUE THEN 'post_liked' ELSE 'post_disliked' END,
+				'post',
+				target.post_id
+			);
+		END IF;
+
+		return NULL;
+	END;
+	$$
+	LANGUAGE plpgsql
+	`
+	commentReactionReputationTriggerFunction := `
+	CREATE OR REPLACE FUNCTION comment_reaction_reputation_handler() RETURNS trigger as $$
+	DECLARE
+		target comments_reactions;
+		direction INTEGER := 1;
+		author INTEGER;
+	BEGIN
+		IF TG_OP = 'INSERT' THEN
+			target := new;
+		ELSE
+			target := old;
+			direction := -1;
+		END IF;
+
+		SELECT created_by INTO author FROM comments WHERE id = target.comment_id;
+
+		IF author IS NOT NULL AND author <> 0 AND author IS DISTINCT FROM target.user_id THEN
+			INSERT INTO reputation_events (user_id, points, reason, source_type, source_id)
+			VALUES (
+				author,
+				direction * CASE WHEN target.reaction = TRUE THEN 5 ELSE -1 END,
+				CASE WHEN target.reaction = TRUE THEN 'comment_liked' ELSE 'comment_disliked' END,
+				'comment',
+				target.comment_id
+			);
+		END IF;
+
+		return NULL;
+	END;
+	$$
+	LANGUAGE plpgsql
+	`
+	// A deleted post's reactions go with it by cascade, when the post can no longer be looked up, so the
+	// points it earned are taken back here instead.
+	deletePostReputationTriggerFunction := `
+	CREATE OR REPLACE FUNCTION delete_post_reputation_handler() RETURNS trigger as $$
+	BEGIN
+		INSERT INTO reputation_events (user_id, points, reason, source_type, source_id)
+		SELECT user_id, -SUM(points), reason, source_type, source_id
+		FROM reputation_events
+		WHERE source_type = 'post' AND source_id = old.id AND reason IN ('post_liked', 'post_disliked')
+		GROUP BY user_id, reason, source_type, source_id
+		HAVING SUM(points) <> 0;
+
+		return old;
+	END;
+	$$
+	LANGUAGE plpgsql
+	`
+	insertReputationEventTriggerFunction := `
+	CREATE OR REPLACE FUNCTION insert_reputation_event_handler() RETURNS trigger as $$
+	BEGIN
+		UPDATE users SET reputation = reputation + new.points WHERE id = new.user_id;
+
+		return new;
+	END;
+	$$
+	LANGUAGE plpgsql
+	`
+
 	resetTopicFTSTrigger := `
 	DROP TRIGGER IF EXISTS topics_ai_au ON topics;
 	`
@@ -230,6 +838,75 @@ func InitDB(db *sql.DB) error {
 	FOR EACH ROW
 	EXECUTE FUNCTION delete_comment_reaction_handler();
 	`
+
+	resetAuditLogAppendOnlyTrigger := `
+	DROP TRIGGER IF EXISTS audit_log_bu_bd ON audit_log;
+	`
+	auditLogAppendOnlyTrigger := `
+	CREATE TRIGGER audit_log_bu_bd
+	BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW
+	EXECUTE FUNCTION audit_log_append_only_handler();
+	`
+
+	resetPostReactionReputationTrigger := `
+	DROP TRIGGER IF EXISTS posts_reactions_reputation_aid ON posts_reactions;
+	`
+	postReactionReputationTrigger := `
+	CREATE TRIGGER posts_reactions_reputation_aid
+	AFTER INSERT OR DELETE ON posts_reactions
+	FOR EACH ROW
+	EXECUTE FUNCTION post_reaction_reputation_handler();
+	`
+	resetCommentReactionReputationTrigger := `
+	DROP TRIGGER IF EXISTS comments_reactions_reputation_aid ON comments_reactions;
+	`
+	commentReactionReputationTrigger := `
+	CREATE TRIGGER comments_reactions_reputation_aid
+	AFTER INSERT OR DELETE ON comments_reactions
+	FOR EACH ROW
+	EXECUTE FUNCTION comment_reaction_reputation_handler();
+	`
+	resetDeletePostReputationTrigger := `
+	DROP TRIGGER IF EXISTS posts_reputation_ad ON posts;
+	`
+	deletePostReputationTrigger := `
+	CREATE TRIGGER posts_reputation_ad
+	AFTER DELETE ON posts
+	FOR EACH ROW
+	EXECUTE FUNCTION delete_post_reputation_handler();
+	`
+	resetInsertReputationEventTrigger := `
+	DROP TRIGGER IF EXISTS reputation_events_ai ON reputation_events;
+	`
+	insertReputationEventTrigger := `
+	CREATE TRIGGER reputation_events_ai
+	AFTER INSERT ON reputation_events
+	FOR EACH ROW
+	EXECUTE FUNCTION insert_reputation_event_handler();
+	`
+	// Credits reactions given before the ledger existed. It only runs while the ledger is empty, so it
+	// happens once, and it needs the trigger above to update the totals.
+	reputationBackfill := `
+	INSERT INTO reputation_events (user_id, points, reason, source_type, source_id, created_at)
+	SELECT p.created_by,
+		CASE WHEN r.reaction = TRUE THEN 10 ELSE -2 END,
+		CASE WHEN r.reaction = TRUE THEN 'post_liked' ELSE 'post_disliked' END,
+		'post', p.id, r.created_at
+	FROM posts_reactions r
+	JOIN posts p ON p.id = r.post_id
+	WHERE p.created_by <> 0 AND p.created_by IS DISTINCT FROM r.user_id
+		AND NOT EXISTS (SELECT 1 FROM reputation_events)
+	UNION ALL
+	SELECT c.created_by,
+		CASE WHEN r.reaction = TRUE THEN 5 ELSE -1 END,
+		CASE WHEN r.reaction = TRUE THEN 'comment_liked' ELSE 'comment_disliked' END,
+		'comment', c.id, r.created_at
+	FROM comments_reactions r
+	JOIN comments c ON c.id = r.comment_id
+	WHERE c.created_by <> 0 AND c.created_by IS DISTINCT FROM r.user_id
+		AND NOT EXISTS (SELECT 1 FROM reputation_events);
+	`
 	//////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 	insertsystemUser := `
@@ -249,6 +926,69 @@ func InitDB(db *sql.DB) error {
 		postReactionTable,
 		commentTable,
 		commentReactionTable,
+		topicWatchTable,
+		postWatchTable,
+		userFollowTable,
+		userBlockTable,
+		userMuteTable,
+		conversationTable,
+		conversationParticipantTable,
+		messageTable,
+		messageIdx,
+		userRoleColumn,
+		reportTable,
+		reportOpenIdx,
+		userWarningTable,
+		userSuspensionTable,
+		bannedIPTable,
+		bannedUsernameTable,
+		auditLogTable,
+		auditLogActorIdx,
+		auditLogTargetIdx,
+		blockedTermTable,
+		heldContentTable,
+		heldContentStatusIdx,
+		loginAttemptTable,
+		loginAttemptUsernameIdx,
+		loginAttemptIPIdx,
+		loginAttemptUserIdx,
+		loginAttemptPendingColumn,
+		userTOTPColumns,
+		recoveryCodeTable,
+		userEmailColumns,
+		dropUserEmailIdx,
+		userEmailIdx,
+		userTokenTable,
+		userIdentityTable,
+		oidcStateTable,
+		oidcStateBrowserColumn,
+		accessTokenTable,
+		userProfileColumns,
+		postCreatedByIdx,
+		commentCreatedByIdx,
+		userHideActivityColumn,
+		postReactionCreatedAtColumn,
+		commentReactionCreatedAtColumn,
+		postReactionUserIdx,
+		commentReactionUserIdx,
+		dataExportTable,
+		dataExportStatusIdx,
+		accountDeletionTable,
+		accountDeletionScheduledIdx,
+		userReputationColumn,
+		reputationEventTable,
+		reputationEventUserIdx,
+		reputationEventSourceIdx,
+		userBadgeTable,
+		userBadgeAwardedAtIdx,
+		topicQAModeColumn,
+		postAcceptedCommentColumn,
+		pollTable,
+		pollOptionTable,
+		pollBallotTable,
+		pollVoteTable,
+		pollVoteOptionIdx,
+		userPasswordChangedColumn,
 	}
 
 	triggers := []string{
@@ -257,6 +997,11 @@ func InitDB(db *sql.DB) error {
 		deletePostReactionTriggerFunction,
 		insertCommentReactionTriggerFunction,
 		deleteCommentReactionTriggerFunction,
+		auditLogAppendOnlyTriggerFunction,
+		postReactionReputationTriggerFunction,
+		commentReactionReputationTriggerFunction,
+		deletePostReputationTriggerFunction,
+		insertReputationEventTriggerFunction,
 		resetTopicFTSTrigger,
 		topicFTSTrigger,
 		resetPostFTSTrigger,
@@ -269,6 +1014,17 @@ func InitDB(db *sql.DB) error {
 		deletePostReactionTrigger,
 		insertCommentReactionTrigger,
 		deleteCommentReactionTrigger,
+		resetAuditLogAppendOnlyTrigger,
+		auditLogAppendOnlyTrigger,
+		resetPostReactionReputationTrigger,
+		postReactionReputationTrigger,
+		resetCommentReactionReputationTrigger,
+		commentReactionReputationTrigger,
+		resetDeletePostReputationTrigger,
+		deletePostReputationTrigger,
+		resetInsertReputationEventTrigger,
+		insertReputationEventTrigger,
+		reputationBackfill,
 	}
 
 	for _, table := range tables {
@@ -0,0 +1,338 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrDuplicateBannedIP = errors.New("banned ip already exists")
+var ErrDuplicateBannedUsername = errors.New("banned username pattern already exists")
+
+func CreateSuspension(db *sql.DB, suspension *models.Suspension) error {
+	suspension.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_suspensions (
+		user_id,
+		reason,
+		expires_at,
+		created_by,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		suspension.UserID,
+		suspension.Reason,
+		suspension.ExpiresAt,
+		suspension.CreatedBy,
+		suspension.CreatedAt,
+	).Scan(&suspension.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// returns the user's active suspension, preferring a permanent ban over a temporary suspension
+func ReadActiveSuspensionByUserID(db *sql.DB, userID int64) (*models.Suspension, error) {
+	suspension := models.Suspension{}
+
+	query := `
+	SELECT id, user_id, reason, expires_at, COALESCE(created_by, 0), created_at, lifted_at
+	FROM users_suspensions
+	WHERE user_id = $1 AND lifted_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	ORDER BY expires_at DESC NULLS FIRST
+	LIMIT 1
+	`
+	err := db.QueryRow(query, userID).Scan(&suspension.ID, &suspension.UserID, &suspension.Reason, &suspension.ExpiresAt, &suspension.CreatedBy, &suspension.CreatedAt, &suspension.LiftedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &suspension, nil
+}
+
+func ReadSuspensionByID(db *sql.DB, id int64) (*models.Suspension, error) {
+	suspension := models.Suspension{}
+
+	query := `
+	SELECT id, user_id, reason, expires_at, COALESCE(created_by, 0), created_at, lifted_at
+	FROM users_suspensions
+	WHERE id = $1
+	`
+	err := db.QueryRow(query, id).Scan(&suspension.ID, &suspension.UserID, &suspension.Reason, &suspension.ExpiresAt, &suspension.CreatedBy, &suspension.CreatedAt, &suspension.LiftedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &suspension, nil
+}
+
+func ReadSuspensionByUserID(db *sql.DB, userID int64, limit int, offset int) ([]models.Suspension, error) {
+	var suspensions []models.Suspension
+
+	query := `
+	SELECT id, user_id, reason, expires_at, COALESCE(created_by, 0), created_at, lifted_at
+	FROM users_suspensions
+	WHERE user_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return suspensions, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var suspension models.Suspension
+
+		if err := rows.Scan(&suspension.ID, &suspension.UserID, &suspension.Reason, &suspension.ExpiresAt, &suspension.CreatedBy, &suspension.CreatedAt, &suspension.LiftedAt); err != nil {
+			return suspensions, err
+		}
+
+		suspensions = append(suspensions, suspension)
+	}
+
+	if err := rows.Err(); err != nil {
+		return suspensions, err
+	}
+
+	return suspensions, nil
+}
+
+func LiftSuspensionByID(db *sql.DB, id int64) (bool, error) {
+	query := "UPDATE users_suspensions SET lifted_at = $1 WHERE id = $2 AND lifted_at IS NULL"
+	res, err := db.Exec(query, time.Now(), id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func CreateBannedIP(db *sql.DB, bannedIP *models.BannedIP) error {
+	bannedIP.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO banned_ips (
+		cidr,
+		reason,
+		created_by,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, cidr::text;
+	`
+	err := db.QueryRow(query, bannedIP.CIDR, bannedIP.Reason, bannedIP.CreatedBy, bannedIP.CreatedAt).Scan(&bannedIP.ID, &bannedIP.CIDR)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateBannedIP
+		}
+		return err
+	}
+
+	return nil
+}
+
+func ReadBannedIP(db *sql.DB, limit int, offset int) ([]models.BannedIP, error) {
+	var bannedIPs []models.BannedIP
+
+	query := `
+	SELECT id, cidr::text, reason, COALESCE(created_by, 0), created_at
+	FROM banned_ips
+	ORDER BY created_at DESC
+	LIMIT $1 OFFSET $2`
+
+	rows, err := db.Query(query, limit, offset)
+
+	if err != nil {
+		return bannedIPs, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var bannedIP models.BannedIP
+
+		if err := rows.Scan(&bannedIP.ID, &bannedIP.CIDR, &bannedIP.Reason, &bannedIP.CreatedBy, &bannedIP.CreatedAt); err != nil {
+			return bannedIPs, err
+		}
+
+		bannedIPs = append(bannedIPs, bannedIP)
+	}
+
+	if err := rows.Err(); err != nil {
+		return bannedIPs, err
+	}
+
+	return bannedIPs, nil
+}
+
+func DeleteBannedIPByID(db *sql.DB, id int64) (bool, error) {
+	query := "DELETE FROM banned_ips WHERE id = $1"
+	res, err := db.Exec(query, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func IsIPBanned(db *sql.DB, ip string) (bool, error) {
+	var banned bool
+
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM banned_ips WHERE $1::inet <<= cidr
+	)
+	`
+	err := db.QueryRow(query, ip).Scan(&banned)
+
+	if err != nil {
+		return false, err
+	}
+
+	return banned, nil
+}
+
+func CreateBannedUsername(db *sql.DB, bannedUsername *models.BannedUsername) error {
+	bannedUsername.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO banned_usernames (
+		pattern,
+		reason,
+		created_by,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id;
+	`
+	err := db.QueryRow(query, bannedUsername.Pattern, bannedUsername.Reason, bannedUsername.CreatedBy, bannedUsername.CreatedAt).Scan(&bannedUsername.ID)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return ErrDuplicateBannedUsername
+		}
+		return err
+	}
+
+	return nil
+}
+
+func ReadBannedUsername(db *sql.DB, limit int, offset int) ([]models.BannedUsername, error) {
+	var bannedUsernames []models.BannedUsername
+
+	query := `
+	SELECT id, pattern, reason, COALESCE(created_by, 0), created_at
+	FROM banned_usernames
+	ORDER BY created_at DESC
+	LIMIT $1 OFFSET $2`
+
+	rows, err := db.Query(query, limit, offset)
+
+	if err != nil {
+		return bannedUsernames, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var bannedUsername models.BannedUsername
+
+		if err := rows.Scan(&bannedUsername.ID, &bannedUsername.Pattern, &bannedUsername.Reason, &bannedUsername.CreatedBy, &bannedUsername.CreatedAt); err != nil {
+			return bannedUsernames, err
+		}
+
+		bannedUsernames = append(bannedUsernames, bannedUsername)
+	}
+
+	if err := rows.Err(); err != nil {
+		return bannedUsernames, err
+	}
+
+	return bannedUsernames, nil
+}
+
+func DeleteBannedUsernameByID(db *sql.DB, id int64) (bool, error) {
+	query := "DELETE FROM banned_usernames WHERE id = $1"
+	res, err := db.Exec(query, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// patterns are Go regular expressions matched case-insensitively against the whole username;
+// patterns that no longer compile are skipped
+func IsUsernameBanned(db *sql.DB, username string) (bool, error) {
+	rows, err := db.Query("SELECT pattern FROM banned_usernames")
+
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var pattern string
+
+		if err := rows.Scan(&pattern); err != nil {
+			return false, err
+		}
+
+		matcher, err := regexp.Compile("(?i)^(?:" + pattern + ")$")
+		if err != nil {
+			continue
+		}
+
+		if matcher.MatchString(username) {
+			return true, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
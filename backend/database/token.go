@@ -0,0 +1,135 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+func CreateUserToken(db *sql.DB, token *models.UserToken) error {
+	token.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO users_tokens (
+		user_id,
+		purpose,
+		token_hash,
+		email,
+		expires_at,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id;
+	`
+	err := db.QueryRow(
+		query,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.Email,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&token.ID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// returns an unused, unexpired token without using it up, or nil when there is no such token
+func ReadUserTokenByHash(db *sql.DB, purpose string, tokenHash string) (*models.UserToken, error) {
+	token := models.UserToken{}
+
+	query := `
+	SELECT id, user_id, purpose, email, expires_at, used_at, created_at
+	FROM users_tokens
+	WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > $3
+	`
+	err := db.QueryRow(query, purpose, tokenHash, time.Now()).Scan(&token.ID, &token.UserID, &token.Purpose, &token.Email, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// marks an unused, unexpired token as used and returns it, or nil when there is no such token
+func ConsumeUserToken(db *sql.DB, purpose string, tokenHash string) (*models.UserToken, error) {
+	token := models.UserToken{}
+
+	query := `
+	UPDATE users_tokens SET used_at = $1
+	WHERE purpose = $2 AND token_hash = $3 AND used_at IS NULL AND expires_at > $1
+	RETURNING id, user_id, purpose, email, expires_at, used_at, created_at
+	`
+	err := db.QueryRow(query, time.Now(), purpose, tokenHash).Scan(&token.ID, &token.UserID, &token.Purpose, &token.Email, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// invalidates every outstanding token of a purpose for the user
+func DeleteUserTokenByUserID(db *sql.DB, userID int64, purpose string) error {
+	query := "DELETE FROM users_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL"
+	_, err := db.Exec(query, userID, purpose)
+
+	return err
+}
+
+// Verifies the user's email, as long as it is still the address the token was sent to. Fails with
+// ErrDuplicateEmail when another account has verified the address first.
+func VerifyEmailByUserID(db *sql.DB, userID int64, email string) (bool, error) {
+	query := `
+	UPDATE users SET email_verified = TRUE
+	WHERE id = $1 AND LOWER(email) = LOWER($2)
+	`
+	res, err := db.Exec(query, userID, email)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "users_verified_email_idx") {
+			return false, ErrDuplicateEmail
+		}
+		return false, err
+	}
+
+	count, _ := res.RowsAffected()
+
+	return count > 0, nil
+}
+
+// only verified addresses can be used to recover an account
+func ReadUserByVerifiedEmail(db *sql.DB, email string) (*models.User, error) {
+	user := models.User{}
+
+	query := `
+	SELECT id, username, email
+	FROM users
+	WHERE LOWER(email) = LOWER($1) AND email_verified = TRUE
+	`
+	err := db.QueryRow(query, email).Scan(&user.ID, &user.Username, &user.Email)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"backend/models"
+	"errors"
+	"testing"
+)
+
+func TestVerifiedEmailIsUnique(t *testing.T) {
+	db := testDB(t)
+
+	email := testName("squat_") + "@example.com"
+
+	first := testUser(t, db)
+	second := testUser(t, db)
+
+	// anyone may enter the address, only proving it makes it theirs
+	for _, userID := range []int64{first.ID, second.ID} {
+		if _, _, err := UpdateUserByID(db, userID, &models.UpdateUserInput{Email: &email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	verified, err := VerifyEmailByUserID(db, second.ID, email)
+	if err != nil || !verified {
+		t.Fatalf("verifying: %v, err %v", verified, err)
+	}
+
+	if _, err := VerifyEmailByUserID(db, first.ID, email); !errors.Is(err, ErrDuplicateEmail) {
+		t.Fatalf("verifying an address another account verified: got %v, want ErrDuplicateEmail", err)
+	}
+
+	user, err := ReadUserByVerifiedEmail(db, email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.ID != second.ID {
+		t.Fatalf("address recovers %+v, want user %d", user, second.ID)
+	}
+}
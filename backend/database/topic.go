@@ -17,9 +17,10 @@ func CreateTopic(db *sql.DB, topic *models.Topic) error {
 		title,
 		description,
 		created_by,
-		created_at
+		created_at,
+		qa_mode
 	)
-	VALUES ($1, $2, $3, $4)
+	VALUES ($1, $2, $3, $4, $5)
 	RETURNING id;
 	`
 	err := db.QueryRow(
@@ -28,6 +29,7 @@ func CreateTopic(db *sql.DB, topic *models.Topic) error {
 		topic.Description,
 		topic.CreatedBy,
 		topic.CreatedAt,
+		topic.QAMode,
 	).Scan(&topic.ID)
 
 	if err != nil {
@@ -41,11 +43,11 @@ func ReadTopicByID(db *sql.DB, id int64) (*models.Topic, error) {
 	topic := models.Topic{}
 
 	query := `
-	SELECT id, title, description, created_by, created_at
+	SELECT id, title, description, created_by, created_at, qa_mode
 	FROM topics
 	WHERE id = $1
 	`
-	err := db.QueryRow(query, id).Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt)
+	err := db.QueryRow(query, id).Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt, &topic.QAMode)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -77,6 +79,13 @@ func UpdateTopicByID(db *sql.DB, id int64, input *models.UpdateTopicInput) (bool
 		counter += 1
 	}
 
+	if input.QAMode != nil {
+		placeholder := strconv.Itoa(counter)
+		updates = append(updates, "qa_mode = $"+placeholder)
+		args = append(args, *input.QAMode)
+		counter += 1
+	}
+
 	if len(updates) == 0 {
 		return true, false, nil
 	}
@@ -130,7 +139,7 @@ func ReadTopicBySearchQuery(db *sql.DB, limit int, offset int, sortBy string, or
 	args := []interface{}{searchQuery}
 
 	query := `
-	SELECT id, title, description, created_by, created_at
+	SELECT id, title, description, created_by, created_at, qa_mode
 	FROM topics, plainto_tsquery('english', $1) AS query
 	WHERE document @@ query
 	`
@@ -153,7 +162,7 @@ func ReadTopicBySearchQuery(db *sql.DB, limit int, offset int, sortBy string, or
 	for rows.Next() {
 		var topic models.Topic
 
-		if err := rows.Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt); err != nil {
+		if err := rows.Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt, &topic.QAMode); err != nil {
 			return topics, err
 		}
 
@@ -172,7 +181,7 @@ func ReadTopic(db *sql.DB, limit int, offset int, sortBy string, order string) (
 	args := []interface{}{}
 
 	query := `
-	SELECT id, title, description, created_by, created_at
+	SELECT id, title, description, created_by, created_at, qa_mode
 	FROM topics
 	`
 
@@ -190,7 +199,7 @@ func ReadTopic(db *sql.DB, limit int, offset int, sortBy string, order string) (
 	for rows.Next() {
 		var topic models.Topic
 
-		if err := rows.Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt); err != nil {
+		if err := rows.Scan(&topic.ID, &topic.Title, &topic.Description, &topic.CreatedBy, &topic.CreatedAt, &topic.QAMode); err != nil {
 			return topics, err
 		}
 
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// returns the user's TOTP secret, whether 2FA is active and the last time step a code was accepted for
+func ReadTOTPByUserID(db *sql.DB, userID int64) (string, bool, int64, error) {
+	var secret sql.NullString
+	var enabled bool
+	var lastStep int64
+
+	query := `
+	SELECT totp_secret, totp_enabled, totp_last_step
+	FROM users
+	WHERE id = $1
+	`
+	err := db.QueryRow(query, userID).Scan(&secret, &enabled, &lastStep)
+
+	if err == sql.ErrNoRows {
+		return "", false, 0, nil
+	}
+
+	if err != nil {
+		return "", false, 0, err
+	}
+
+	return secret.String, enabled, lastStep, nil
+}
+
+// stores a new secret awaiting activation, leaving 2FA off until a code from it is verified
+func UpdateTOTPSecretByUserID(db *sql.DB, userID int64, secret string) error {
+	query := `
+	UPDATE users SET
+		totp_secret = $1,
+		totp_enabled = FALSE,
+		totp_last_step = 0
+	WHERE id = $2
+	`
+	_, err := db.Exec(query, secret, userID)
+
+	return err
+}
+
+// records the time step of an accepted code, returning false if it (or a later one) was already used
+func UseTOTPStep(db *sql.DB, userID int64, step int64) (bool, error) {
+	query := `
+	UPDATE users SET totp_last_step = $1
+	WHERE id = $2 AND totp_last_step < $1
+	`
+	res, err := db.Exec(query, step, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, _ := res.RowsAffected()
+
+	return count > 0, nil
+}
+
+func insertRecoveryCodes(tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec("DELETE FROM users_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO users_recovery_codes (
+		user_id,
+		code_hash,
+		created_at
+	)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, code_hash) DO NOTHING;
+	`
+	now := time.Now()
+
+	for _, codeHash := range codeHashes {
+		if _, err := tx.Exec(query, userID, codeHash, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// turns 2FA on and replaces any previous recovery codes
+func EnableTOTPByUserID(db *sql.DB, userID int64, step int64, codeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE users SET
+		totp_enabled = TRUE,
+		totp_last_step = $1
+	WHERE id = $2
+	`
+	if _, err := tx.Exec(query, step, userID); err != nil {
+		return err
+	}
+
+	if err := insertRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func ReplaceRecoveryCodes(db *sql.DB, userID int64, codeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func DisableTOTPByUserID(db *sql.DB, userID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE users SET
+		totp_secret = NULL,
+		totp_enabled = FALSE,
+		totp_last_step = 0
+	WHERE id = $1
+	`
+	if _, err := tx.Exec(query, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM users_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// marks an unused recovery code as used, returning false when no such code is available
+func UseRecoveryCode(db *sql.DB, userID int64, codeHash string) (bool, error) {
+	query := `
+	UPDATE users_recovery_codes SET used_at = $1
+	WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL
+	`
+	res, err := db.Exec(query, time.Now(), userID, codeHash)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, _ := res.RowsAffected()
+
+	return count > 0, nil
+}
@@ -4,11 +4,14 @@ import (
 	"backend/models"
 	"backend/utils"
 	"database/sql"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var ErrDuplicateEmail = errors.New("email already in use")
+
 func CreateUser(db *sql.DB, user *models.User) error {
 
 	hash, hashingErr := utils.HashingPassword(user.Password)
@@ -26,17 +29,20 @@ func CreateUser(db *sql.DB, user *models.User) error {
 		username,
 		password_hash,
 		created_at,
-		last_active
+		last_active,
+		email
 	)
-	VALUES ($1, $2, $3, $4);
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id;
 	`
-	_, err := db.Exec(
+	err := db.QueryRow(
 		query,
 		user.Username,
 		user.PasswordHash,
 		user.CreatedAt,
 		user.LastActive,
-	)
+		user.Email,
+	).Scan(&user.ID)
 
 	if err != nil {
 		return err
@@ -52,11 +58,13 @@ func ReadUserByID(db *sql.DB, id int64) (*models.User, error) {
 	user := models.User{}
 
 	query := `
-	SELECT id, username, password_hash, created_at, last_active
+	SELECT id, username, password_hash, created_at, last_active, role, totp_enabled, email, email_verified,
+		display_name, bio, avatar_url, location, website, hide_activity
 	FROM users
 	WHERE id = $1
 	`
-	err := db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastActive)
+	err := db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastActive, &user.Role, &user.TOTPEnabled, &user.Email, &user.EmailVerified,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.Location, &user.Website, &user.HideActivity)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -73,11 +81,13 @@ func ReadUserByUsername(db *sql.DB, username string) (*models.User, error) {
 	user := models.User{}
 
 	query := `
-	SELECT id, username, password_hash, created_at, last_active
+	SELECT id, username, password_hash, created_at, last_active, role, totp_enabled, email, email_verified,
+		display_name, bio, avatar_url, location, website, hide_activity
 	FROM users
 	WHERE username = $1
 	`
-	err := db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastActive)
+	err := db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.LastActive, &user.Role, &user.TOTPEnabled, &user.Email, &user.EmailVerified,
+		&user.DisplayName, &user.Bio, &user.AvatarURL, &user.Location, &user.Website, &user.HideActivity)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -109,6 +119,41 @@ func UpdateUserByID(db *sql.DB, id int64, input *models.UpdateUserInput) (bool,
 		counter += 1
 	}
 
+	// a changed email has to be verified again, and an empty one removes it
+	if input.Email != nil {
+		placeholder := strconv.Itoa(counter)
+		updates = append(updates, "email = NULLIF($"+placeholder+", ''), email_verified = FALSE")
+		args = append(args, *input.Email)
+		counter += 1
+	}
+
+	profileFields := []struct {
+		column string
+		value  *string
+	}{
+		{"display_name", input.DisplayName},
+		{"bio", input.Bio},
+		{"avatar_url", input.AvatarURL},
+		{"location", input.Location},
+		{"website", input.Website},
+	}
+
+	for _, field := range profileFields {
+		if field.value != nil {
+			placeholder := strconv.Itoa(counter)
+			updates = append(updates, field.column+" = $"+placeholder)
+			args = append(args, *field.value)
+			counter += 1
+		}
+	}
+
+	if input.HideActivity != nil {
+		placeholder := strconv.Itoa(counter)
+		updates = append(updates, "hide_activity = $"+placeholder)
+		args = append(args, *input.HideActivity)
+		counter += 1
+	}
+
 	if input.Password != nil {
 		placeholder := strconv.Itoa(counter)
 		hash, err := utils.HashingPassword(*input.Password)
@@ -140,34 +185,83 @@ func UpdateUserByID(db *sql.DB, id int64, input *models.UpdateUserInput) (bool,
 	return false, false, nil
 }
 
-func DeleteUserByID(db *sql.DB, id int64) (bool, error) {
-	query := "DELETE FROM users WHERE id = $1"
-	res, err := db.Exec(query, id)
+func GetUserOwnerByID(db *sql.DB, userID int64) (int64, error) {
+	return userID, nil
+}
+
+func ReadUsernameByID(db *sql.DB, userID int64) (string, error) {
+	var username string
+
+	query := `
+	SELECT username
+	FROM users
+	WHERE id = $1
+	`
+	err := db.QueryRow(query, userID).Scan(&username)
+
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
 
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	if count, _ := res.RowsAffected(); count == 0 {
-		return true, nil
+	return username, nil
+}
+
+// when the password was last reset, or nil if never; sessions issued before it are no longer valid
+func ReadPasswordChangedAtByUserID(db *sql.DB, userID int64) (*time.Time, error) {
+	var changedAt *time.Time
+
+	query := `
+	SELECT password_changed_at
+	FROM users
+	WHERE id = $1
+	`
+	err := db.QueryRow(query, userID).Scan(&changedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
 
-	return false, nil
+	if err != nil {
+		return nil, err
+	}
+
+	return changedAt, nil
 }
 
-func GetUserOwnerByID(db *sql.DB, userID int64) (int64, error) {
-	return userID, nil
+// signs the user out everywhere: existing sessions stop working and access tokens are deleted
+func RevokeCredentialsByUserID(db *sql.DB, userID int64) error {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE users SET password_changed_at = $1 WHERE id = $2", time.Now(), userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM users_access_tokens WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func ReadUsernameByID(db *sql.DB, userID int64) (string, error) {
-	var username string
+func ReadUserRoleByID(db *sql.DB, userID int64) (string, error) {
+	var role string
 
 	query := `
-	SELECT username
+	SELECT role
 	FROM users
 	WHERE id = $1
 	`
-	err := db.QueryRow(query, userID).Scan(&username)
+	err := db.QueryRow(query, userID).Scan(&role)
 
 	if err == sql.ErrNoRows {
 		return "", nil
@@ -177,5 +271,20 @@ func ReadUsernameByID(db *sql.DB, userID int64) (string, error) {
 		return "", err
 	}
 
-	return username, nil
+	return role, nil
+}
+
+func UpdateUserRoleByID(db *sql.DB, userID int64, role string) (bool, error) {
+	query := "UPDATE users SET role = $1 WHERE id = $2"
+	res, err := db.Exec(query, role, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
 }
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"backend/models"
+	"database/sql"
+	"time"
+)
+
+// creates the watch or changes its level if the user already watches the topic
+func UpsertTopicWatch(db *sql.DB, watch *models.TopicWatch) error {
+	watch.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO topics_watches (
+		topic_id,
+		user_id,
+		level,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (topic_id, user_id) DO UPDATE SET level = EXCLUDED.level
+	RETURNING id, created_at;
+	`
+	err := db.QueryRow(
+		query,
+		watch.TopicID,
+		watch.UserID,
+		watch.Level,
+		watch.CreatedAt,
+	).Scan(&watch.ID, &watch.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ReadTopicWatchByTopicIDAndUserID(db *sql.DB, topicID int64, userID int64) (*models.TopicWatch, error) {
+	watch := models.TopicWatch{}
+
+	query := `
+	SELECT id, topic_id, user_id, level, created_at
+	FROM topics_watches
+	WHERE topic_id = $1 AND user_id = $2
+	`
+	err := db.QueryRow(query, topicID, userID).Scan(&watch.ID, &watch.TopicID, &watch.UserID, &watch.Level, &watch.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &watch, nil
+}
+
+func DeleteTopicWatchByTopicIDAndUserID(db *sql.DB, topicID int64, userID int64) (bool, error) {
+	query := "DELETE FROM topics_watches WHERE topic_id = $1 AND user_id = $2"
+	res, err := db.Exec(query, topicID, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// creates the watch or changes its level if the user already watches the post
+func UpsertPostWatch(db *sql.DB, watch *models.PostWatch) error {
+	watch.CreatedAt = time.Now()
+
+	query := `
+	INSERT INTO posts_watches (
+		post_id,
+		user_id,
+		level,
+		created_at
+	)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (post_id, user_id) DO UPDATE SET level = EXCLUDED.level
+	RETURNING id, created_at;
+	`
+	err := db.QueryRow(
+		query,
+		watch.PostID,
+		watch.UserID,
+		watch.Level,
+		watch.CreatedAt,
+	).Scan(&watch.ID, &watch.CreatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ReadPostWatchByPostIDAndUserID(db *sql.DB, postID int64, userID int64) (*models.PostWatch, error) {
+	watch := models.PostWatch{}
+
+	query := `
+	SELECT id, post_id, user_id, level, created_at
+	FROM posts_watches
+	WHERE post_id = $1 AND user_id = $2
+	`
+	err := db.QueryRow(query, postID, userID).Scan(&watch.ID, &watch.PostID, &watch.UserID, &watch.Level, &watch.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &watch, nil
+}
+
+func DeletePostWatchByPostIDAndUserID(db *sql.DB, postID int64, userID int64) (bool, error) {
+	query := "DELETE FROM posts_watches WHERE post_id = $1 AND user_id = $2"
+	res, err := db.Exec(query, postID, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	if count, _ := res.RowsAffected(); count == 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// posts from watched topics and individually watched posts, most recently active first.
+// muted posts, posts in muted topics and posts by muted or blocked authors are left out.
+func ReadWatchedPost(db *sql.DB, userID int64, limit int, offset int) ([]models.WatchedPost, error) {
+	var posts []models.WatchedPost
+
+	query := `
+	SELECT p.id, p.title, p.description, p.topic_id, p.likes, p.dislikes, p.is_edited, p.views, p.popularity, p.created_by, p.created_at, p.accepted_comment_id, p.accepted_comment_id IS NOT NULL,
+		GREATEST(p.created_at, COALESCE((SELECT MAX(c.created_at) FROM comments c WHERE c.post_id = p.id), p.created_at)) AS last_activity
+	FROM posts p
+	LEFT JOIN topics_watches tw ON tw.topic_id = p.topic_id AND tw.user_id = $1
+	LEFT JOIN posts_watches pw ON pw.post_id = p.id AND pw.user_id = $1
+	WHERE (tw.level IN ('all', 'replies') OR pw.level IN ('all', 'replies'))
+		AND (tw.level IS NULL OR tw.level <> 'muted')
+		AND (pw.level IS NULL OR pw.level <> 'muted')
+		AND NOT ` + hiddenAuthorCondition("p.created_by", "$1") + `
+	ORDER BY last_activity DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := db.Query(query, userID, limit, offset)
+
+	if err != nil {
+		return posts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var post models.WatchedPost
+
+		if err := rows.Scan(&post.ID, &post.Title, &post.Description, &post.TopicID, &post.Likes, &post.Dislikes, &post.IsEdited, &post.Views, &post.Popularity, &post.CreatedBy, &post.CreatedAt, &post.AcceptedCommentID, &post.IsAnswered, &post.LastActivity); err != nil {
+			return posts, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return posts, err
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,54 @@
+package deletion
+
+import (
+	"backend/database"
+	"database/sql"
+	"log"
+	"os"
+	"time"
+)
+
+// ACCOUNT_DELETION_DELAY (default 14 days) is how long a deletion can still be cancelled
+func Delay() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("ACCOUNT_DELETION_DELAY")); err == nil && value > 0 {
+		return value
+	}
+
+	return 14 * 24 * time.Hour
+}
+
+// Starts the background worker. Deletions are scheduled in the database, so the worker only has to
+// look for due ones; a deletion that fails part way is rolled back and retried on the next run.
+func Start(db *sql.DB) {
+	go work(db)
+}
+
+func work(db *sql.DB) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		for {
+			deletion, paths, err := database.ProcessAccountDeletion(db)
+
+			if err != nil {
+				log.Println("account deletion failed:", err)
+				break
+			}
+
+			if deletion == nil {
+				break
+			}
+
+			log.Println("deleted account", deletion.UserID, "mode", deletion.Mode)
+
+			for _, path := range paths {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					log.Println(err)
+				}
+			}
+		}
+
+		<-ticker.C
+	}
+}
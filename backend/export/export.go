@@ -0,0 +1,262 @@
+package export
+
+import (
+	"archive/zip"
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// how long a finished archive can be downloaded before it is deleted
+const archiveLifetime = 7 * 24 * time.Hour
+
+// a running export older than this is assumed to belong to a server that died
+const staleAfter = time.Hour
+
+// how many rows are read per query for the paged tables
+const pageSize = 500
+
+var wake = make(chan struct{}, 1)
+
+// Archives are written to EXPORT_DIR (default "exports"), which the server must be able to create.
+func dir() string {
+	if exportDir := os.Getenv("EXPORT_DIR"); exportDir != "" {
+		return exportDir
+	}
+
+	return "exports"
+}
+
+// Starts the background worker. Exports are queued in the database, so ones requested before a
+// restart are picked up again here.
+func Start(db *sql.DB) {
+	go work(db)
+}
+
+// tells the worker a new export is waiting
+func Notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func work(db *sql.DB) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := database.RequeueStaleDataExports(db, time.Now().Add(-staleAfter)); err != nil {
+			log.Println(err)
+		}
+
+		purgeExpired(db)
+
+		for {
+			export, err := database.ClaimDataExport(db)
+
+			if err != nil {
+				log.Println(err)
+				break
+			}
+
+			if export == nil {
+				break
+			}
+
+			run(db, export)
+		}
+
+		select {
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func run(db *sql.DB, export *models.DataExport) {
+	if err := os.MkdirAll(dir(), 0o700); err != nil {
+		fail(db, export, err)
+		return
+	}
+
+	path := filepath.Join(dir(), "export-"+strconv.FormatInt(export.ID, 10)+".zip")
+	partial := path + ".part"
+
+	if err := writeArchive(db, export.UserID, partial); err != nil {
+		os.Remove(partial)
+		fail(db, export, err)
+		return
+	}
+
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		fail(db, export, err)
+		return
+	}
+
+	if err := database.CompleteDataExportByID(db, export.ID, path, time.Now().Add(archiveLifetime)); err != nil {
+		log.Println(err)
+	}
+}
+
+func fail(db *sql.DB, export *models.DataExport, err error) {
+	log.Println("data export", export.ID, "failed:", err)
+
+	if err := database.FailDataExportByID(db, export.ID, "Export failed, please try again"); err != nil {
+		log.Println(err)
+	}
+}
+
+func purgeExpired(db *sql.DB) {
+	paths, err := database.DeleteExpiredDataExports(db)
+
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Println(err)
+		}
+	}
+}
+
+func addJSON(archive *zip.Writer, name string, data interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(data)
+}
+
+// reads every page of a paged query
+func readAll[T any](read func(limit int, offset int) ([]T, error)) ([]T, error) {
+	all := []T{}
+
+	for offset := 0; ; offset += pageSize {
+		page, err := read(pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, page...)
+
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+func writeArchive(db *sql.DB, userID int64, path string) error {
+	user, err := database.ReadUserByID(db, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %d no longer exists", userID)
+	}
+
+	topics, err := database.ReadTopicByUserID(db, userID)
+	if err != nil {
+		return err
+	}
+
+	posts, err := readAll(func(limit int, offset int) ([]models.Post, error) {
+		return database.ReadPostByUserID(db, userID, limit, offset, "created_at", "ASC")
+	})
+	if err != nil {
+		return err
+	}
+
+	comments, err := readAll(func(limit int, offset int) ([]models.Comment, error) {
+		return database.ReadCommentByUserID(db, userID, limit, offset, "created_at", "ASC")
+	})
+	if err != nil {
+		return err
+	}
+
+	reactions, err := readAll(func(limit int, offset int) ([]models.UserReaction, error) {
+		return database.ReadReactionByUserID(db, userID, limit, offset, "ASC")
+	})
+	if err != nil {
+		return err
+	}
+
+	reputation, err := readAll(func(limit int, offset int) ([]models.ReputationEvent, error) {
+		return database.ReadReputationEventByUserID(db, userID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+
+	messages, err := database.ReadMessageBySenderID(db, userID)
+	if err != nil {
+		return err
+	}
+
+	logins, err := readAll(func(limit int, offset int) ([]models.LoginAttempt, error) {
+		return database.ReadLoginAttemptByUserID(db, userID, limit, offset)
+	})
+	if err != nil {
+		return err
+	}
+
+	accessTokens, err := database.ReadAccessTokenByUserID(db, userID)
+	if err != nil {
+		return err
+	}
+
+	identities, err := database.ReadIdentityByUserID(db, userID)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	contents := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", user},
+		{"topics.json", topics},
+		{"posts.json", posts},
+		{"comments.json", comments},
+		{"reactions.json", reactions},
+		{"reputation.json", reputation},
+		{"messages.json", messages},
+		{"logins.json", logins},
+		{"access_tokens.json", accessTokens},
+		{"identities.json", identities},
+	}
+
+	for _, content := range contents {
+		if err := addJSON(archive, content.name, content.data); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"archive/zip"
+	"backend/database"
+	"backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Connects to the database named by TEST_DATABASE_URL and creates the schema. Tests that need a database
+// are skipped without it; never point it at a database whose data you want to keep.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connection := os.Getenv("TEST_DATABASE_URL")
+	if connection == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("pgx", connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.InitDB(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestReadAll(t *testing.T) {
+	rows := make([]int, 2*pageSize+3)
+	for i := range rows {
+		rows[i] = i
+	}
+
+	calls := 0
+	all, err := readAll(func(limit int, offset int) ([]int, error) {
+		calls++
+		end := min(offset+limit, len(rows))
+		return rows[offset:end], nil
+	})
+
+	if err != nil || len(all) != len(rows) || all[len(all)-1] != len(rows)-1 || calls != 3 {
+		t.Fatalf("read %d rows in %d calls, err %v", len(all), calls, err)
+	}
+
+	// an exact multiple of the page size needs one more, empty, page to know it has finished
+	rows = rows[:pageSize]
+	calls = 0
+	all, _ = readAll(func(limit int, offset int) ([]int, error) {
+		calls++
+		end := min(offset+limit, len(rows))
+		return rows[min(offset, end):end], nil
+	})
+
+	if len(all) != pageSize || calls != 2 {
+		t.Fatalf("read %d rows in %d calls", len(all), calls)
+	}
+
+	if _, err := readAll(func(limit int, offset int) ([]int, error) {
+		return nil, errors.New("query failed")
+	}); err == nil {
+		t.Fatalf("query error swallowed")
+	}
+}
+
+func TestRun(t *testing.T) {
+	db := testDB(t)
+	t.Setenv("EXPORT_DIR", t.TempDir())
+
+	bytes := make([]byte, 6)
+	rand.Read(bytes)
+
+	user := models.User{Username: "test_" + hex.EncodeToString(bytes), Password: "correct horse battery staple"}
+	if err := database.CreateUser(db, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	topic := models.Topic{Title: "exported topic", Description: "exported topic", CreatedBy: user.ID}
+	if err := database.CreateTopic(db, &topic); err != nil {
+		t.Fatal(err)
+	}
+
+	export := models.DataExport{UserID: user.ID}
+	if err := database.CreateDataExport(db, &export); err != nil {
+		t.Fatal(err)
+	}
+
+	run(db, &export)
+
+	finished, err := database.ReadDataExportByID(db, export.ID, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if finished.Status != models.ExportReady || finished.ExpiresAt == nil {
+		t.Fatalf("export %+v, want it ready with an expiry", finished)
+	}
+
+	archive, err := zip.OpenReader(finished.FilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var data json.RawMessage
+		if err := json.NewDecoder(reader).Decode(&data); err != nil {
+			t.Fatalf("%s is not JSON: %v", file.Name, err)
+		}
+		reader.Close()
+
+		files[file.Name] = string(data)
+	}
+
+	if len(files) != 10 {
+		t.Errorf("archive has %d files, want 10", len(files))
+	}
+
+	if !strings.Contains(files["profile.json"], user.Username) || strings.Contains(files["profile.json"], "$argon2id$") {
+		t.Errorf("profile.json is %s", files["profile.json"])
+	}
+
+	if !strings.Contains(files["topics.json"], "exported topic") {
+		t.Errorf("topics.json is %s", files["topics.json"])
+	}
+
+	if _, err := os.Stat(finished.FilePath + ".part"); !os.IsNotExist(err) {
+		t.Errorf("partial archive left behind")
+	}
+}
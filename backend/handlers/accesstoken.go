@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"time"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAccessTokenDays = 30
+	maxAccessTokenDays     = 365
+)
+
+// the token itself is only returned here, once
+func CreateAccessTokenHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.CreateAccessTokenInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Name == "" || len(input.Scopes) == 0 {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		if input.ExpiresInDays == 0 {
+			input.ExpiresInDays = defaultAccessTokenDays
+		}
+
+		if input.ExpiresInDays < 0 || input.ExpiresInDays > maxAccessTokenDays {
+			c.JSON(400, gin.H{"error": "Expiry must be between 1 and 365 days"})
+			return
+		}
+
+		scopes := []string{}
+		moderate := false
+
+		for _, scope := range input.Scopes {
+			valid := false
+			for _, known := range models.AccessTokenScopes {
+				if scope == known {
+					valid = true
+				}
+			}
+
+			if !valid {
+				c.JSON(400, gin.H{"error": "Invalid scope", "scope": scope})
+				return
+			}
+
+			if scope == models.ScopeModerate {
+				moderate = true
+			}
+
+			duplicate := false
+			for _, added := range scopes {
+				if scope == added {
+					duplicate = true
+				}
+			}
+
+			if !duplicate {
+				scopes = append(scopes, scope)
+			}
+		}
+
+		if moderate {
+			role, err := database.ReadUserRoleByID(db, userID)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if role != models.RoleModerator && role != models.RoleAdmin {
+				c.JSON(403, gin.H{"error": "Only moderators can create moderation tokens"})
+				return
+			}
+		}
+
+		tokenStr, tokenHash, err := auth.GenerateAccessToken()
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+
+		token := models.AccessToken{
+			UserID:    userID,
+			Name:      input.Name,
+			TokenHash: tokenHash,
+			Scopes:    scopes,
+			ExpiresAt: &expiresAt,
+		}
+
+		if err := database.CreateAccessToken(db, &token); err != nil {
+			c.JSON(500, gin.H{"error": "Could not create token"})
+			return
+		}
+
+		c.Set("audit_target_type", "access_token")
+		c.Set("audit_target_id", token.ID)
+
+		c.JSON(201, gin.H{
+			"id":         token.ID,
+			"name":       token.Name,
+			"scopes":     token.Scopes,
+			"expires_at": token.ExpiresAt,
+			"token":      tokenStr,
+		})
+	}
+}
+
+func ReadAccessTokenHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		tokens, err := database.ReadAccessTokenByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":  len(tokens),
+			"tokens": tokens,
+		})
+	}
+}
+
+func DeleteAccessTokenByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		strTokenID := c.Param("token_id")
+		tokenID, err := strconv.ParseInt(strTokenID, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		tokenNotFound, err := database.DeleteAccessTokenByID(db, tokenID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not revoke token"})
+			return
+		}
+
+		if tokenNotFound {
+			c.JSON(404, gin.H{"error": "Token not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Token revoked"})
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checks that the user exists and that the viewer may see their activity, answering the request otherwise
+func checkActivityVisible(c *gin.Context, db *sql.DB, userID int64) bool {
+	user, err := database.ReadUserByID(db, userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	if user == nil {
+		c.JSON(404, gin.H{"error": "User not found"})
+		return false
+	}
+
+	var viewerID int64
+	if userIDVal, exists := c.Get("user_id"); exists {
+		viewerID, _ = userIDVal.(int64)
+	}
+
+	if user.HideActivity && viewerID != userID {
+		c.JSON(403, gin.H{"error": "This user's activity is private"})
+		return false
+	}
+
+	return true
+}
+
+func ReadPostByUserIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || userID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		sortBy := c.DefaultQuery("sort_by", "created_at")
+		order := c.DefaultQuery("order", "DESC")
+
+		if sortBy != "created_at" && sortBy != "popularity" && sortBy != "views" {
+			sortBy = "created_at"
+		}
+
+		if order != "ASC" && order != "DESC" {
+			order = "DESC"
+		}
+
+		if !checkActivityVisible(c, db, userID) {
+			return
+		}
+
+		postsData, err := database.ReadPostByUserID(db, userID, limit, offset, sortBy, order)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(postsData) == 0 {
+			postsData = []models.Post{}
+		}
+
+		c.JSON(200, gin.H{
+			"count":   len(postsData),
+			"page":    page,
+			"limit":   limit,
+			"sort_by": sortBy,
+			"order":   order,
+			"posts":   postsData,
+		})
+	}
+}
+
+func ReadCommentByUserIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || userID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		sortBy := c.DefaultQuery("sort_by", "created_at")
+		order := c.DefaultQuery("order", "DESC")
+
+		if sortBy != "created_at" && sortBy != "likes" {
+			sortBy = "created_at"
+		}
+
+		if order != "ASC" && order != "DESC" {
+			order = "DESC"
+		}
+
+		if !checkActivityVisible(c, db, userID) {
+			return
+		}
+
+		commentsData, err := database.ReadCommentByUserID(db, userID, limit, offset, sortBy, order)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(commentsData) == 0 {
+			commentsData = []models.Comment{}
+		}
+
+		c.JSON(200, gin.H{
+			"count":    len(commentsData),
+			"page":     page,
+			"limit":    limit,
+			"sort_by":  sortBy,
+			"order":    order,
+			"comments": commentsData,
+		})
+	}
+}
+
+// the reactions a user gave, only ever shown to the user themselves
+func ReadReactionByUserIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || userID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		order := c.DefaultQuery("order", "DESC")
+
+		if order != "ASC" && order != "DESC" {
+			order = "DESC"
+		}
+
+		reactionsData, err := database.ReadReactionByUserID(db, userID, limit, offset, order)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":     len(reactionsData),
+			"page":      page,
+			"limit":     limit,
+			"sort_by":   "created_at",
+			"order":     order,
+			"reactions": reactionsData,
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lets the author of a post in a Q&A topic accept one of its top-level comments as the answer
+func UpdateAcceptedAnswerHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid post ID"})
+			return
+		}
+
+		var input models.AcceptAnswerInput
+
+		if err := c.ShouldBindJSON(&input); err != nil || input.CommentID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		qaMode, err := database.ReadTopicQAModeByPostID(db, postID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !qaMode {
+			c.JSON(409, gin.H{"error": "Answers can only be accepted in Q&A topics"})
+			return
+		}
+
+		comment, err := database.ReadCommentByID(db, input.CommentID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if comment == nil || comment.PostID != postID {
+			c.JSON(404, gin.H{"error": "Comment not found"})
+			return
+		}
+
+		if comment.ParentCommentID != nil {
+			c.JSON(400, gin.H{"error": "Only top-level comments can be accepted"})
+			return
+		}
+
+		// Description == "" indicates soft-deleted comment
+		if comment.Description == "" {
+			c.JSON(403, gin.H{"error": "Deleted comments cannot be accepted"})
+			return
+		}
+
+		post_not_found, err := database.UpdateAcceptedAnswerByPostID(db, postID, &input.CommentID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not accept answer"})
+			return
+		}
+
+		if post_not_found {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		c.Set("audit_target_type", "comment")
+		c.Set("audit_target_id", input.CommentID)
+
+		c.JSON(200, gin.H{"status": "Answer accepted", "accepted_comment_id": input.CommentID})
+	}
+}
+
+func DeleteAcceptedAnswerHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid post ID"})
+			return
+		}
+
+		post_not_found, err := database.UpdateAcceptedAnswerByPostID(db, postID, nil)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not remove accepted answer"})
+			return
+		}
+
+		if post_not_found {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Accepted answer removed"})
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"encoding/json"
+	"log"
+
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reads actor_id, target_type, target_id, from and to (RFC 3339) query parameters
+func parseAuditFilter(c *gin.Context) (*models.AuditFilter, string) {
+	filter := models.AuditFilter{
+		TargetType: c.DefaultQuery("target_type", ""),
+	}
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := strconv.ParseInt(actorIDStr, 10, 64)
+		if err != nil {
+			return nil, "Invalid actor ID"
+		}
+		filter.ActorID = actorID
+	}
+
+	if targetIDStr := c.Query("target_id"); targetIDStr != "" {
+		targetID, err := strconv.ParseInt(targetIDStr, 10, 64)
+		if err != nil {
+			return nil, "Invalid target ID"
+		}
+		filter.TargetID = targetID
+	}
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return nil, "Invalid from time"
+		}
+		filter.From = &from
+	}
+
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return nil, "Invalid to time"
+		}
+		filter.To = &to
+	}
+
+	return &filter, ""
+}
+
+func ReadAuditLogHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, errMsg := parseAuditFilter(c)
+		if filter == nil {
+			c.JSON(400, gin.H{"error": errMsg})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		entriesData, err := database.ReadAuditLog(db, filter, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(entriesData) == 0 {
+			c.JSON(200, gin.H{
+				"count":   0,
+				"page":    page,
+				"limit":   limit,
+				"entries": []models.AuditEntry{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":   len(entriesData),
+			"page":    page,
+			"limit":   limit,
+			"entries": entriesData,
+		})
+	}
+}
+
+// streams the filtered audit log as JSON Lines, one entry per line
+func ExportAuditLogHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, errMsg := parseAuditFilter(c)
+		if filter == nil {
+			c.JSON(400, gin.H{"error": errMsg})
+			return
+		}
+
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit_log.jsonl"`)
+		c.Status(200)
+
+		encoder := json.NewEncoder(c.Writer)
+
+		err := database.ExportAuditLog(db, filter, func(entry *models.AuditEntry) error {
+			return encoder.Encode(entry)
+		})
+
+		// headers are already sent, so a failure can only cut the stream short
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
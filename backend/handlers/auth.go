@@ -2,13 +2,17 @@ package handlers
 
 import (
 	"database/sql"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"backend/auth"
 	"backend/database"
 	"backend/models"
+	"backend/utils"
 )
 
 func LoginHandler(db *sql.DB) gin.HandlerFunc {
@@ -21,6 +25,20 @@ func LoginHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		retryAfter, err := auth.LoginRetryAfter(db, loginData.Username, c.ClientIP())
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if retryAfter > 0 {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(429, gin.H{"error": "Too many failed login attempts", "retry_after": seconds})
+			return
+		}
+
 		userData, err := database.ReadUserByUsername(db, loginData.Username)
 
 		if err != nil {
@@ -28,48 +46,176 @@ func LoginHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		attempt := models.LoginAttempt{
+			Username:  loginData.Username,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+
+		// unknown usernames and wrong passwords get the same response, in the same time
 		if userData == nil {
-			c.JSON(404, gin.H{"error": "User not found"})
+			auth.CheckDummyPassword(loginData.Password)
+			err = sql.ErrNoRows
+		} else {
+			attempt.UserID = &userData.ID
+			_, err = auth.CheckLoginValidity(userData, &loginData)
+		}
+
+		if err != nil {
+			if !recordLoginAttempt(c, db, &attempt) {
+				return
+			}
+
+			c.JSON(401, gin.H{"error": "Invalid username or password"})
 			return
 		}
 
-		userID, err := auth.CheckLoginValidity(userData, &loginData)
+		// hashes from an older scheme or older parameters are replaced while the password is at hand
+		if utils.NeedsRehash(userData.PasswordHash) {
+			if _, _, err := database.UpdateUserByID(db, userData.ID, &models.UpdateUserInput{Password: &loginData.Password}); err != nil {
+				log.Println(err)
+			}
+		}
 
-		if err != nil {
-			c.JSON(400, gin.H{"error": "Wrong password"})
+		completeLogin(c, db, userData, &attempt, false)
+	}
+}
+
+// Finishes a login once the user is identified: banned users are refused, users with 2FA get a pending
+// token until secondFactorDone, and everyone else gets a session. Suspended users may still log in to read.
+// The attempt is recorded as a success only once the session is issued.
+func completeLogin(c *gin.Context, db *sql.DB, user *models.User, attempt *models.LoginAttempt, secondFactorDone bool) {
+	suspension, err := database.ReadActiveSuspensionByUserID(db, user.ID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	var suspendedUntil interface{}
+	if suspension != nil {
+		if suspension.ExpiresAt == nil {
+			if !recordLoginAttempt(c, db, attempt) {
+				return
+			}
+
+			c.JSON(403, gin.H{"error": "Account banned", "reason": suspension.Reason})
 			return
 		}
+		suspendedUntil = suspension.ExpiresAt
+	}
 
-		tokenStr, err := auth.GenerateJWT(userID)
+	if user.TOTPEnabled && !secondFactorDone {
+		attempt.Pending = true
+
+		if !recordLoginAttempt(c, db, attempt) {
+			return
+		}
+
+		pendingToken, err := auth.GeneratePendingJWT(user.ID)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Could not generate token"})
 			return
 		}
 
-		c.SetSameSite(http.SameSiteNoneMode)
+		c.JSON(200, gin.H{"two_factor_required": true, "pending_token": pendingToken})
+		return
+	}
+
+	if !startSession(c, user.ID) {
+		return
+	}
+
+	attempt.Success = true
 
-		c.SetCookie(
-			"token",
-			tokenStr,
-			3600,
-			"/",
-			"",
-			true,
-			true,
-		)
+	if !recordLoginAttempt(c, db, attempt) {
+		return
+	}
+
+	csrfToken, err := issueCSRFToken(c)
 
-		c.JSON(200, gin.H{"user_id": userID})
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
 	}
+
+	c.JSON(200, gin.H{"user_id": user.ID, "suspended_until": suspendedUntil, "csrf_token": csrfToken})
+}
+
+// writes a login attempt to the history, answering the request itself on failure
+func recordLoginAttempt(c *gin.Context, db *sql.DB, attempt *models.LoginAttempt) bool {
+	if err := database.CreateLoginAttempt(db, attempt); err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	return true
+}
+
+// sets the session cookie for a fully authenticated user, answering the request itself on failure
+func startSession(c *gin.Context, userID int64) bool {
+	c.Set("user_id", userID)
+
+	tokenStr, err := auth.GenerateJWT(userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not generate token"})
+		return false
+	}
+
+	c.SetSameSite(http.SameSiteNoneMode)
+
+	c.SetCookie(
+		"token",
+		tokenStr,
+		3600,
+		"/",
+		"",
+		true,
+		true,
+	)
+
+	return true
+}
+
+// Sets a fresh CSRF cookie and returns the token. The frontend runs on another origin and can't read
+// the cookie, so it keeps the returned token and echoes it in the X-CSRF-Token header.
+func issueCSRFToken(c *gin.Context) (string, error) {
+	token, _, err := auth.GenerateToken()
+
+	if err != nil {
+		return "", err
+	}
+
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(auth.CSRFCookieName, token, 24*3600, "/", "", true, true)
+
+	return token, nil
 }
 
 func LogoutHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.SetCookie("token", "", -1, "/", "", false, true)
+		c.SetCookie(auth.CSRFCookieName, "", -1, "/", "", false, true)
 		c.JSON(200, gin.H{"status": "Logged out"})
 	}
 }
 
+// hands out a CSRF token for the X-CSRF-Token header, e.g. after the frontend reloads and loses it
+func ReadCSRFTokenHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		csrfToken, err := issueCSRFToken(c)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{"csrf_token": csrfToken})
+	}
+}
+
 func ReadLoggedInUserID(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if userIDval, exists := c.Get("user_id"); exists {
@@ -99,3 +245,64 @@ func ReadLoggedInUserID(db *sql.DB) gin.HandlerFunc {
 		}
 	}
 }
+
+// the user's own login history, most recent first
+func ReadLoginAttemptHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		attemptsData, err := database.ReadLoginAttemptByUserID(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(attemptsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":  0,
+				"page":   page,
+				"limit":  limit,
+				"logins": []models.LoginAttempt{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":  len(attemptsData),
+			"page":   page,
+			"limit":  limit,
+			"logins": attemptsData,
+		})
+	}
+}
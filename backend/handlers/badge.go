@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"backend/badges"
+	"backend/database"
+	"database/sql"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReadBadgeHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{"badges": badges.Definitions()})
+	}
+}
+
+func ReadRecentBadgeHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		awarded, err := database.ReadRecentBadge(db, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		badges.Describe(awarded)
+
+		c.JSON(200, gin.H{
+			"count":  len(awarded),
+			"page":   page,
+			"limit":  limit,
+			"badges": awarded,
+		})
+	}
+}
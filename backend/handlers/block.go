@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateBlockHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		blockedID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || blockedID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if userID == blockedID {
+			c.JSON(400, gin.H{"error": "Cannot block yourself"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, blockedID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if username == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		block := models.Block{
+			BlockerID: userID,
+			BlockedID: blockedID,
+		}
+
+		err = database.CreateBlock(db, &block)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateBlock) {
+				c.JSON(409, gin.H{"error": "User is already blocked"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not block user"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User blocked"})
+	}
+}
+
+func DeleteBlockHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		blockedID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || blockedID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		block_not_found, err := database.DeleteBlockByBlockerIDAndBlockedID(db, userID, blockedID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unblock user"})
+			return
+		}
+
+		if block_not_found {
+			c.JSON(404, gin.H{"error": "Block not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User unblocked"})
+	}
+}
+
+func CreateMuteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		mutedID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || mutedID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if userID == mutedID {
+			c.JSON(400, gin.H{"error": "Cannot mute yourself"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, mutedID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if username == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		mute := models.Mute{
+			MuterID: userID,
+			MutedID: mutedID,
+		}
+
+		err = database.CreateMute(db, &mute)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateMute) {
+				c.JSON(409, gin.H{"error": "User is already muted"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not mute user"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User muted"})
+	}
+}
+
+func DeleteMuteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		mutedID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || mutedID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		mute_not_found, err := database.DeleteMuteByMuterIDAndMutedID(db, userID, mutedID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unmute user"})
+			return
+		}
+
+		if mute_not_found {
+			c.JSON(404, gin.H{"error": "Mute not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User unmuted"})
+	}
+}
+
+func ReadBlockedHandler(db *sql.DB) gin.HandlerFunc {
+	return readRelatedUsersHandler(db, database.ReadBlockedByUserID, "blocked")
+}
+
+func ReadMutedHandler(db *sql.DB) gin.HandlerFunc {
+	return readRelatedUsersHandler(db, database.ReadMutedByUserID, "muted")
+}
+
+type relatedUsersFetcher func(*sql.DB, int64, int, int) ([]models.RelatedUser, error)
+
+// lists the logged in user's own blocks or mutes
+func readRelatedUsersHandler(db *sql.DB, fetcher relatedUsersFetcher, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		usersData, err := fetcher(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(usersData) == 0 {
+			c.JSON(200, gin.H{
+				"count": 0,
+				"page":  page,
+				"limit": limit,
+				key:     []models.RelatedUser{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count": len(usersData),
+			"page":  page,
+			"limit": limit,
+			key:     usersData,
+		})
+	}
+}
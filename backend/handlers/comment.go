@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"backend/contentfilter"
 	"backend/database"
 	"backend/models"
+	"backend/utils"
 	"database/sql"
 	"errors"
 	"log"
@@ -41,6 +43,63 @@ func CreateCommentHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		post, err := database.ReadPostByID(db, input.PostID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if post == nil {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		blocked, err := database.IsBlockedBy(db, post.CreatedBy, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !blocked && input.ParentCommentID != nil {
+			parentComment, err := database.ReadCommentByID(db, *input.ParentCommentID)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if parentComment == nil {
+				c.JSON(404, gin.H{"error": "Comment not found"})
+				return
+			}
+
+			blocked, err = database.IsBlockedBy(db, parentComment.CreatedBy, userID)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot reply to this user"})
+			return
+		}
+
+		blocked, err = database.IsBlockedByAnyUsername(db, utils.ExtractMentions(input.Description), userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot mention this user"})
+			return
+		}
+
 		comment := models.Comment{
 			Description:     input.Description,
 			PostID:          input.PostID,
@@ -48,11 +107,24 @@ func CreateCommentHandler(db *sql.DB) gin.HandlerFunc {
 			CreatedBy:       userID,
 		}
 
+		content := contentfilter.Content{
+			Kind:   models.TargetTypeComment,
+			UserID: userID,
+			Body:   input.Description,
+		}
+
+		if !applyContentFilter(c, db, &content, comment) {
+			return
+		}
+
 		if err := database.CreateComment(db, &comment); err != nil {
 			c.JSON(500, gin.H{"error": "Could not create comment"})
 			return
 		}
 
+		c.Set("audit_target_type", "comment")
+		c.Set("audit_target_id", comment.ID)
+
 		var checked_parent_comment_id interface{}
 
 		if comment.ParentCommentID == nil {
@@ -239,18 +311,36 @@ func ReadCommentByPostIDHandler(db *sql.DB) gin.HandlerFunc {
 
 		offset := (page - 1) * limit
 
-		sortBy := c.DefaultQuery("sort_by", "created_at")
+		qaMode, err := database.ReadTopicQAModeByPostID(db, postID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// answers in a Q&A topic are ranked by score, other comments read in time order
+		defaultSortBy := "created_at"
+		if qaMode {
+			defaultSortBy = "score"
+		}
+
+		sortBy := c.DefaultQuery("sort_by", defaultSortBy)
 		order := c.DefaultQuery("order", "DESC")
 
-		if sortBy != "created_at" && sortBy != "likes" {
-			sortBy = "created_at"
+		if sortBy != "created_at" && sortBy != "likes" && sortBy != "score" {
+			sortBy = defaultSortBy
 		}
 
 		if order != "ASC" && order != "DESC" {
 			order = "DESC"
 		}
 
-		commentsData, err := database.ReadCommentByPostID(db, postID, limit, offset, sortBy, order)
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		commentsData, err := database.ReadCommentByPostID(db, postID, viewerID, limit, offset, sortBy, order)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
@@ -326,12 +416,28 @@ func CreateCommentReactionHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		blocked, err := database.IsBlockedBy(db, comment.CreatedBy, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot react to this user"})
+			return
+		}
+
 		var input models.CreateCommentReactionInput
 		if err := c.ShouldBindJSON(&input); err != nil {
 			c.JSON(400, gin.H{"error": "Invalid payload"})
 			return
 		}
 
+		if !input.Reaction && !checkPrivilege(c, db, userID, models.PrivilegeDislike) {
+			return
+		}
+
 		commentReaction := models.CommentReaction{
 			CommentID: commentID,
 			UserID:    userID,
@@ -510,7 +616,12 @@ func ReadCommentByParentCommentIDHandler(db *sql.DB) gin.HandlerFunc {
 			order = "DESC"
 		}
 
-		commentsData, err := database.ReadCommentByParentCommentID(db, parentCommentID, limit, offset, sortBy, order)
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		commentsData, err := database.ReadCommentByParentCommentID(db, parentCommentID, viewerID, limit, offset, sortBy, order)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
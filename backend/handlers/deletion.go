@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/deletion"
+	"backend/models"
+	"database/sql"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Schedules the account for deletion after the cooling-off period instead of deleting it straight away.
+// The body is optional; mode defaults to anonymize.
+func DeleteUserByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.DeleteAccountInput
+
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Mode == "" {
+			input.Mode = models.DeletionAnonymize
+		}
+
+		if input.Mode != models.DeletionAnonymize && input.Mode != models.DeletionDelete {
+			c.JSON(400, gin.H{"error": "Mode must be anonymize or delete"})
+			return
+		}
+
+		existing, err := database.ReadAccountDeletionByUserID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if existing != nil {
+			c.JSON(409, gin.H{"error": "Account deletion already scheduled", "scheduled_for": existing.ScheduledFor})
+			return
+		}
+
+		accountDeletion := models.AccountDeletion{
+			UserID:       id,
+			Mode:         input.Mode,
+			ScheduledFor: time.Now().Add(deletion.Delay()),
+		}
+
+		if err := database.CreateAccountDeletion(db, &accountDeletion); err != nil {
+			c.JSON(500, gin.H{"error": "Could not schedule deletion"})
+			return
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", id)
+
+		c.JSON(202, accountDeletion)
+	}
+}
+
+func ReadAccountDeletionHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		accountDeletion, err := database.ReadAccountDeletionByUserID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if accountDeletion == nil {
+			c.JSON(404, gin.H{"error": "No deletion scheduled"})
+			return
+		}
+
+		c.JSON(200, accountDeletion)
+	}
+}
+
+func CancelAccountDeletionHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		deletion_not_found, err := database.DeleteAccountDeletionByUserID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not cancel deletion"})
+			return
+		}
+
+		if deletion_not_found {
+			c.JSON(404, gin.H{"error": "No deletion scheduled"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Deletion cancelled"})
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/mailer"
+	"backend/models"
+	"database/sql"
+	"errors"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const verifyEmailTokenLifetime = 24 * time.Hour
+const resetPasswordTokenLifetime = time.Hour
+
+// links in emails point at the frontend, which posts the token back to the API
+func appURL(path string, token string) string {
+	base := os.Getenv("APP_URL")
+	if base == "" {
+		base = "https://cvwo-chatit.onrender.com"
+	}
+
+	return strings.TrimRight(base, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// accepts a bare address only, so display names and header tricks never reach the mailer
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+
+	return err == nil && address.Address == email
+}
+
+// creates a single-use token for the user and emails it to them
+func sendTokenEmail(db *sql.DB, userID int64, email string, purpose string, lifetime time.Duration, subject string, body func(link string) string, path string) error {
+	tokenStr, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	token := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     email,
+		ExpiresAt: time.Now().Add(lifetime),
+	}
+
+	if err := database.CreateUserToken(db, &token); err != nil {
+		return err
+	}
+
+	return mailer.Send(email, subject, body(appURL(path, tokenStr)))
+}
+
+func sendVerificationEmail(db *sql.DB, userID int64, email string) error {
+	return sendTokenEmail(db, userID, email, models.TokenPurposeVerifyEmail, verifyEmailTokenLifetime,
+		"Verify your email address",
+		func(link string) string {
+			return "Confirm this address for your account by opening the link below.\n\n" + link + "\n\nThe link expires in 24 hours."
+		},
+		"/verify-email",
+	)
+}
+
+func sendPasswordResetEmail(db *sql.DB, userID int64, email string) error {
+	return sendTokenEmail(db, userID, email, models.TokenPurposeResetPassword, resetPasswordTokenLifetime,
+		"Reset your password",
+		func(link string) string {
+			return "Someone asked to reset the password for your account. If it was you, open the link below to choose a new one.\n\n" + link + "\n\nThe link expires in 1 hour. If you did not ask for this, you can ignore this email."
+		},
+		"/reset-password",
+	)
+}
+
+func RequestEmailVerificationHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		user, err := database.ReadUserByID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if user == nil {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		if user.Email == nil {
+			c.JSON(400, gin.H{"error": "No email address set"})
+			return
+		}
+
+		if user.EmailVerified {
+			c.JSON(409, gin.H{"error": "Email is already verified"})
+			return
+		}
+
+		if err := database.DeleteUserTokenByUserID(db, userID, models.TokenPurposeVerifyEmail); err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err := sendVerificationEmail(db, userID, *user.Email); err != nil {
+			log.Println(err)
+			c.JSON(500, gin.H{"error": "Could not send verification email"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Verification email sent"})
+	}
+}
+
+func VerifyEmailHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.VerifyEmailInput
+
+		if err := c.ShouldBindJSON(&input); err != nil || input.Token == "" {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		token, err := database.ConsumeUserToken(db, models.TokenPurposeVerifyEmail, auth.HashToken(input.Token))
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if token == nil {
+			c.JSON(400, gin.H{"error": "Invalid or expired link"})
+			return
+		}
+
+		verified, err := database.VerifyEmailByUserID(db, token.UserID, token.Email)
+
+		// only someone holding the address gets this far, so telling them it is taken gives nothing away
+		if errors.Is(err, database.ErrDuplicateEmail) {
+			c.JSON(409, gin.H{"error": "Email already verified on another account"})
+			return
+		}
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not verify email"})
+			return
+		}
+
+		if !verified {
+			c.JSON(400, gin.H{"error": "Email has changed since this link was sent"})
+			return
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", token.UserID)
+
+		c.JSON(200, gin.H{"status": "Email verified"})
+	}
+}
+
+// answers the same way whether or not the address belongs to an account
+func ForgotPasswordHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.ForgotPasswordInput
+
+		if err := c.ShouldBindJSON(&input); err != nil || input.Email == "" {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		user, err := database.ReadUserByVerifiedEmail(db, input.Email)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// the link is sent in the background so known and unknown addresses answer in the same time
+		if user != nil {
+			go func(userID int64, email string) {
+				// only the latest link works
+				if err := database.DeleteUserTokenByUserID(db, userID, models.TokenPurposeResetPassword); err != nil {
+					log.Println(err)
+					return
+				}
+
+				if err := sendPasswordResetEmail(db, userID, email); err != nil {
+					log.Println(err)
+				}
+			}(user.ID, *user.Email)
+		}
+
+		c.JSON(200, gin.H{"status": "If an account uses this email, a reset link has been sent"})
+	}
+}
+
+func ResetPasswordHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.ResetPasswordInput
+
+		if err := c.ShouldBindJSON(&input); err != nil || input.Token == "" {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Password == "" {
+			c.JSON(400, gin.H{"error": "Password cannot be empty"})
+			return
+		}
+
+		// the policy is checked before using up the link, so a rejected password can be retried
+		pending, err := database.ReadUserTokenByHash(db, models.TokenPurposeResetPassword, auth.HashToken(input.Token))
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if pending == nil {
+			c.JSON(400, gin.H{"error": "Invalid or expired link"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, pending.UserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !checkPasswordPolicy(c, input.Password, username) {
+			return
+		}
+
+		token, err := database.ConsumeUserToken(db, models.TokenPurposeResetPassword, auth.HashToken(input.Token))
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if token == nil {
+			c.JSON(400, gin.H{"error": "Invalid or expired link"})
+			return
+		}
+
+		_, user_not_found, err := database.UpdateUserByID(db, token.UserID, &models.UpdateUserInput{Password: &input.Password})
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not reset password"})
+			return
+		}
+
+		if user_not_found {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		// whoever knew the old password may still be signed in
+		if err := database.RevokeCredentialsByUserID(db, token.UserID); err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", token.UserID)
+
+		c.JSON(200, gin.H{"status": "Password reset"})
+	}
+}
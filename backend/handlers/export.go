@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/export"
+	"backend/models"
+	"database/sql"
+	"math"
+	"time"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// building an archive reads everything a user ever wrote, so each user gets one a day
+const dataExportInterval = 24 * time.Hour
+
+func CreateDataExportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		latest, err := database.ReadLatestDataExportByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if latest != nil {
+			if wait := time.Until(latest.CreatedAt.Add(dataExportInterval)); wait > 0 {
+				seconds := int(math.Ceil(wait.Seconds()))
+				c.Header("Retry-After", strconv.Itoa(seconds))
+				c.JSON(429, gin.H{
+					"error":       "Only one export per day",
+					"retry_after": seconds,
+					"export_id":   latest.ID,
+				})
+				return
+			}
+		}
+
+		dataExport := models.DataExport{UserID: userID}
+
+		if err := database.CreateDataExport(db, &dataExport); err != nil {
+			c.JSON(500, gin.H{"error": "Could not start export"})
+			return
+		}
+
+		export.Notify()
+
+		c.Set("audit_target_type", "data_export")
+		c.Set("audit_target_id", dataExport.ID)
+
+		c.JSON(202, dataExport)
+	}
+}
+
+func ReadDataExportByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		strExportID := c.Param("export_id")
+		exportID, err := strconv.ParseInt(strExportID, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		dataExport, err := database.ReadDataExportByID(db, exportID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if dataExport == nil {
+			c.JSON(404, gin.H{"error": "Export not found"})
+			return
+		}
+
+		c.JSON(200, dataExport)
+	}
+}
+
+func DownloadDataExportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		strExportID := c.Param("export_id")
+		exportID, err := strconv.ParseInt(strExportID, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		dataExport, err := database.ReadDataExportByID(db, exportID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if dataExport == nil || (dataExport.ExpiresAt != nil && dataExport.ExpiresAt.Before(time.Now())) {
+			c.JSON(404, gin.H{"error": "Export not found"})
+			return
+		}
+
+		if dataExport.Status != models.ExportReady {
+			c.JSON(409, gin.H{"error": "Export is not ready", "status": dataExport.Status})
+			return
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.FileAttachment(dataExport.FilePath, "chatit-export-"+strExportID+".zip")
+	}
+}
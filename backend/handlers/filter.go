@@ -0,0 +1,439 @@
+package handlers
+
+import (
+	"backend/contentfilter"
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Runs the content filter and reports whether the content may be published. Otherwise the request has
+// already been answered: throttled content gets 429, rejected content 422, and held content is queued for
+// moderators with 202. payload is stored as-is and created once a moderator approves it.
+func applyContentFilter(c *gin.Context, db *sql.DB, content *contentfilter.Content, payload interface{}) bool {
+	result, err := contentfilter.Check(db, content)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	switch result.Verdict {
+	case contentfilter.Throttle:
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(429, gin.H{"error": result.Reason, "retry_after": retryAfter})
+		return false
+	case contentfilter.Reject:
+		c.JSON(422, gin.H{"error": "Content rejected", "reason": result.Reason})
+		return false
+	case contentfilter.Hold:
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return false
+		}
+
+		held := models.HeldContent{
+			Kind:    content.Kind,
+			UserID:  content.UserID,
+			Payload: payloadJSON,
+			Reason:  result.Reason,
+		}
+
+		if err := database.CreateHeldContent(db, &held); err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return false
+		}
+
+		c.Set("audit_target_type", "held_content")
+		c.Set("audit_target_id", held.ID)
+
+		c.JSON(202, gin.H{
+			"status": "Held for review",
+			"id":     held.ID,
+			"reason": held.Reason,
+		})
+		return false
+	}
+
+	return true
+}
+
+func ReadHeldContentHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := c.DefaultQuery("status", models.HeldStatusPending)
+		kind := c.DefaultQuery("kind", "")
+
+		if status != models.HeldStatusPending && status != models.HeldStatusApproved && status != models.HeldStatusRejected {
+			c.JSON(400, gin.H{"error": "Invalid status"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		heldData, err := database.ReadHeldContent(db, status, kind, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(heldData) == 0 {
+			c.JSON(200, gin.H{
+				"count": 0,
+				"page":  page,
+				"limit": limit,
+				"held":  []models.HeldContent{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count": len(heldData),
+			"page":  page,
+			"limit": limit,
+			"held":  heldData,
+		})
+	}
+}
+
+// creates the content a held item stands for and returns it
+func publishHeldContent(db *sql.DB, held *models.HeldContent) (interface{}, error) {
+	switch held.Kind {
+	case models.TargetTypeTopic:
+		var topic models.Topic
+		if err := json.Unmarshal(held.Payload, &topic); err != nil {
+			return nil, err
+		}
+		if err := database.CreateTopic(db, &topic); err != nil {
+			return nil, err
+		}
+		return topic, nil
+	case models.TargetTypePost:
+		var post models.Post
+		if err := json.Unmarshal(held.Payload, &post); err != nil {
+			return nil, err
+		}
+		if err := database.CreatePost(db, &post); err != nil {
+			return nil, err
+		}
+
+		watch := models.PostWatch{
+			PostID: post.ID,
+			UserID: post.CreatedBy,
+			Level:  models.WatchLevelAll,
+		}
+
+		if err := database.UpsertPostWatch(db, &watch); err != nil {
+			log.Println(err)
+		}
+		return post, nil
+	case models.TargetTypeComment:
+		var comment models.Comment
+		if err := json.Unmarshal(held.Payload, &comment); err != nil {
+			return nil, err
+		}
+		if err := database.CreateComment(db, &comment); err != nil {
+			return nil, err
+		}
+		return comment, nil
+	}
+
+	return nil, errors.New("unknown held content kind")
+}
+
+func ApproveHeldContentHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("held_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		moderatorID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		held, err := database.ReadHeldContentByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if held == nil {
+			c.JSON(404, gin.H{"error": "Held content not found"})
+			return
+		}
+
+		// claiming the item first stops two moderators publishing it twice
+		not_pending, err := database.ReviewHeldContentByID(db, id, models.HeldStatusApproved, moderatorID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not approve content"})
+			return
+		}
+
+		if not_pending {
+			c.JSON(409, gin.H{"error": "Held content has already been reviewed"})
+			return
+		}
+
+		published, err := publishHeldContent(db, held)
+
+		if err != nil {
+			log.Println(err)
+			if err := database.ReopenHeldContentByID(db, id); err != nil {
+				log.Println(err)
+			}
+			c.JSON(500, gin.H{"error": "Could not publish content"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"status":    "Content approved",
+			"kind":      held.Kind,
+			"published": published,
+		})
+	}
+}
+
+func RejectHeldContentHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("held_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		moderatorID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		held, err := database.ReadHeldContentByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if held == nil {
+			c.JSON(404, gin.H{"error": "Held content not found"})
+			return
+		}
+
+		not_pending, err := database.ReviewHeldContentByID(db, id, models.HeldStatusRejected, moderatorID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not reject content"})
+			return
+		}
+
+		if not_pending {
+			c.JSON(409, gin.H{"error": "Held content has already been reviewed"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Content rejected"})
+	}
+}
+
+func CreateBlockedTermHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.CreateBlockedTermInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Action == "" {
+			input.Action = models.BlockedTermActionHold
+		}
+
+		if input.Action != models.BlockedTermActionHold && input.Action != models.BlockedTermActionReject {
+			c.JSON(400, gin.H{"error": "Invalid action"})
+			return
+		}
+
+		if !contentfilter.IsValidPattern(input.Pattern, input.IsRegex) {
+			c.JSON(400, gin.H{"error": "Invalid pattern"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		term := models.BlockedTerm{
+			Pattern:   input.Pattern,
+			IsRegex:   input.IsRegex,
+			Action:    input.Action,
+			CreatedBy: userID,
+		}
+
+		err := database.CreateBlockedTerm(db, &term)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateBlockedTerm) {
+				c.JSON(409, gin.H{"error": "Term is already blocked"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not block term"})
+			return
+		}
+
+		c.Set("audit_target_type", "blocked_term")
+		c.Set("audit_target_id", term.ID)
+
+		c.JSON(201, term)
+	}
+}
+
+func ReadBlockedTermHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		termsData, err := database.ReadBlockedTerm(db, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(termsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":         0,
+				"page":          page,
+				"limit":         limit,
+				"blocked_terms": []models.BlockedTerm{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":         len(termsData),
+			"page":          page,
+			"limit":         limit,
+			"blocked_terms": termsData,
+		})
+	}
+}
+
+func DeleteBlockedTermByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("blocked_term_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		blocked_term_not_found, err := database.DeleteBlockedTermByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unblock term"})
+			return
+		}
+
+		if blocked_term_not_found {
+			c.JSON(404, gin.H{"error": "Blocked term not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Term unblocked"})
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateFollowHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		followeeID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || followeeID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if userID == followeeID {
+			c.JSON(400, gin.H{"error": "Cannot follow yourself"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, followeeID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if username == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		follow := models.Follow{
+			FollowerID: userID,
+			FolloweeID: followeeID,
+		}
+
+		err = database.CreateFollow(db, &follow)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateFollow) {
+				c.JSON(409, gin.H{"error": "User is already followed"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not follow user"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User followed"})
+	}
+}
+
+func DeleteFollowHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		followeeID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || followeeID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		follow_not_found, err := database.DeleteFollowByFollowerIDAndFolloweeID(db, userID, followeeID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unfollow user"})
+			return
+		}
+
+		if follow_not_found {
+			c.JSON(404, gin.H{"error": "Follow not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "User unfollowed"})
+	}
+}
+
+func ReadFollowerHandler(db *sql.DB) gin.HandlerFunc {
+	return readFollowUsersHandler(db, database.ReadFollowerByUserID, "followers")
+}
+
+func ReadFollowingHandler(db *sql.DB) gin.HandlerFunc {
+	return readFollowUsersHandler(db, database.ReadFollowingByUserID, "following")
+}
+
+type followUsersFetcher func(*sql.DB, int64, int, int) ([]models.FollowUser, error)
+
+func readFollowUsersHandler(db *sql.DB, fetcher followUsersFetcher, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || userID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		usersData, err := fetcher(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(usersData) == 0 {
+			c.JSON(200, gin.H{
+				"count": 0,
+				"page":  page,
+				"limit": limit,
+				key:     []models.FollowUser{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count": len(usersData),
+			"page":  page,
+			"limit": limit,
+			key:     usersData,
+		})
+	}
+}
+
+func ReadHomeFeedHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		// created_at ranks by recency, popularity by engagement
+		sortBy := c.DefaultQuery("sort_by", "created_at")
+		order := c.DefaultQuery("order", "DESC")
+
+		if sortBy != "created_at" && sortBy != "popularity" {
+			sortBy = "created_at"
+		}
+
+		if order != "ASC" && order != "DESC" {
+			order = "DESC"
+		}
+
+		postsData, err := database.ReadHomeFeed(db, userID, limit, offset, sortBy, order)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(postsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":   0,
+				"page":    page,
+				"limit":   limit,
+				"sort_by": sortBy,
+				"order":   order,
+				"posts":   []models.Post{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":   len(postsData),
+			"page":    page,
+			"limit":   limit,
+			"sort_by": sortBy,
+			"order":   order,
+			"posts":   postsData,
+		})
+	}
+}
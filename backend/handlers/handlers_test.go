@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	if os.Getenv("JWT_SECRET") == "" && os.Getenv("JWT_PRIVATE_KEY_FILE") == "" {
+		os.Setenv("JWT_SECRET", "handlers-test-secret-handlers-test-secret")
+	}
+
+	if err := auth.LoadKeys(); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
+
+var (
+	testDBOnce sync.Once
+	testDBConn *sql.DB
+	testDBErr  error
+)
+
+// Connects to the database named by TEST_DATABASE_URL and creates the schema once per run. Tests that need
+// a database are skipped without it; never point it at a database whose data you want to keep.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	connection := os.Getenv("TEST_DATABASE_URL")
+	if connection == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = sql.Open("pgx", connection)
+		if testDBErr == nil {
+			testDBErr = database.InitDB(testDBConn)
+		}
+	})
+
+	if testDBErr != nil {
+		t.Fatal(testDBErr)
+	}
+
+	return testDBConn
+}
+
+// a name no other test run has used, since tests share the database
+func testName(prefix string) string {
+	bytes := make([]byte, 6)
+	rand.Read(bytes)
+
+	return prefix + hex.EncodeToString(bytes)
+}
+
+func decodeBody(t *testing.T, recorder *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %q: %v", recorder.Body.String(), err)
+	}
+
+	return body
+}
+
+// creates a user with the given role ("" for a regular user)
+func testUser(t *testing.T, db *sql.DB, role string) models.User {
+	t.Helper()
+
+	user := models.User{Username: testName("test_"), Password: "correct horse battery staple"}
+
+	if err := database.CreateUser(db, &user); err != nil {
+		t.Fatal(err)
+	}
+
+	if role != "" {
+		if _, err := db.Exec("UPDATE users SET role = $1 WHERE id = $2", role, user.ID); err != nil {
+			t.Fatal(err)
+		}
+		user.Role = role
+	}
+
+	return user
+}
+
+// a router acting as the given user, standing in for the auth and role middleware
+func routerAs(user models.User) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Set("role", user.Role)
+	})
+
+	return router
+}
+
+func serveJSON(router *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
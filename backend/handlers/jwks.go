@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"backend/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publishes the public keys sessions are signed with, for services verifying them
+func ReadJWKSHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(200, auth.JWKS())
+	}
+}
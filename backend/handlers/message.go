@@ -0,0 +1,500 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateConversationHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.CreateConversationInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		participantIDs := []int64{}
+		seen := map[int64]bool{userID: true}
+
+		for _, participantID := range input.ParticipantIDs {
+			if participantID <= 0 {
+				c.JSON(400, gin.H{"error": "Invalid participant ID"})
+				return
+			}
+
+			if seen[participantID] {
+				continue
+			}
+			seen[participantID] = true
+
+			username, err := database.ReadUsernameByID(db, participantID)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if username == "" {
+				c.JSON(404, gin.H{"error": "User not found"})
+				return
+			}
+
+			participantIDs = append(participantIDs, participantID)
+		}
+
+		if len(participantIDs) == 0 {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		blocked, err := database.IsBlockedBetweenUsers(db, userID, participantIDs)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot message this user"})
+			return
+		}
+
+		conversation := models.Conversation{
+			CreatedBy: userID,
+		}
+
+		if err := database.CreateConversation(db, &conversation, participantIDs); err != nil {
+			c.JSON(500, gin.H{"error": "Could not create conversation"})
+			return
+		}
+
+		conversationData, err := database.ReadConversationByID(db, conversation.ID, userID)
+
+		if err != nil || conversationData == nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(201, conversationData)
+	}
+}
+
+func ReadConversationHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		conversationsData, err := database.ReadConversationByUserID(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(conversationsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":         0,
+				"page":          page,
+				"limit":         limit,
+				"conversations": []models.Conversation{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":         len(conversationsData),
+			"page":          page,
+			"limit":         limit,
+			"conversations": conversationsData,
+		})
+	}
+}
+
+// participants' read receipts are included with the conversation
+func ReadConversationByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("conversation_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		conversation, err := database.ReadConversationByID(db, id, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if conversation == nil {
+			c.JSON(404, gin.H{"error": "Conversation not found"})
+			return
+		}
+
+		c.JSON(200, conversation)
+	}
+}
+
+func ReadUnreadMessageCountHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		count, err := database.ReadUnreadMessageCountByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{"unread_count": count})
+	}
+}
+
+func CreateMessageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("conversation_id")
+		conversationID, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.CreateMessageInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Body == "" {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		blocked, err := database.IsBlockedInConversation(db, conversationID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot message this user"})
+			return
+		}
+
+		message := models.Message{
+			ConversationID: conversationID,
+			SenderID:       userID,
+			Body:           input.Body,
+		}
+
+		if err := database.CreateMessage(db, &message); err != nil {
+			c.JSON(500, gin.H{"error": "Could not send message"})
+			return
+		}
+
+		c.Set("audit_target_type", "message")
+		c.Set("audit_target_id", message.ID)
+
+		c.JSON(201, message)
+	}
+}
+
+func ReadMessageHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("conversation_id")
+		conversationID, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		cursorStr := c.DefaultQuery("cursor", "0")
+		limitStr := c.DefaultQuery("limit", "20")
+
+		cursor, err := strconv.ParseInt(cursorStr, 10, 64)
+		if err != nil || cursor < 0 {
+			c.JSON(400, gin.H{"error": "Invalid cursor"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 20
+		}
+
+		messagesData, err := database.ReadMessageByConversationID(db, conversationID, cursor, limit)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// a full page means there may be older messages behind the last one
+		var nextCursor interface{}
+		if len(messagesData) == limit {
+			nextCursor = messagesData[len(messagesData)-1].ID
+		}
+
+		if len(messagesData) == 0 {
+			c.JSON(200, gin.H{
+				"count":       0,
+				"limit":       limit,
+				"next_cursor": nextCursor,
+				"messages":    []models.Message{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":       len(messagesData),
+			"limit":       limit,
+			"next_cursor": nextCursor,
+			"messages":    messagesData,
+		})
+	}
+}
+
+func UpdateMessageByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("message_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		message, err := database.ReadMessageByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if message == nil {
+			c.JSON(404, gin.H{"error": "Message not found"})
+			return
+		}
+
+		// Body == "" indicates soft-deleted message
+		if message.Body == "" {
+			c.JSON(403, gin.H{"error": "Update on deleted message is not allowed"})
+			return
+		}
+
+		var input models.UpdateMessageInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Body != nil && *input.Body == "" {
+			c.JSON(400, gin.H{"error": "Body cannot be empty"})
+			return
+		}
+
+		empty_update, message_not_found, err := database.UpdateMessageByID(db, id, &input)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not update message"})
+			return
+		}
+
+		if empty_update {
+			c.JSON(400, gin.H{"error": "Empty update"})
+			return
+		}
+
+		if message_not_found {
+			c.JSON(404, gin.H{"error": "Message not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Updated successfully"})
+	}
+}
+
+func DeleteMessageByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("message_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		message_not_found, err := database.DeleteMessageByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not delete message"})
+			return
+		}
+
+		if message_not_found {
+			c.JSON(404, gin.H{"error": "Message not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Message deleted"})
+	}
+}
+
+func UpdateReadReceiptHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("conversation_id")
+		conversationID, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.ReadReceiptInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.MessageID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid message ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		message_not_found, err := database.UpdateReadReceipt(db, conversationID, userID, input.MessageID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not update read receipt"})
+			return
+		}
+
+		if message_not_found {
+			c.JSON(404, gin.H{"error": "Message not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Marked as read"})
+	}
+}
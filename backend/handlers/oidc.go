@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/models"
+	"backend/oidc"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const oidcStateLifetime = 10 * time.Minute
+
+// holds a random value whose hash is stored with the state, so only the browser that started a login
+// can finish it
+const oidcBrowserCookieName = "oidc_browser"
+
+// Stores a fresh state for this login, bound to this browser by a cookie, and returns the provider URL
+// to send the user to.
+func startOIDC(c *gin.Context, db *sql.DB, linkUserID *int64) {
+	provider, err := oidc.ProviderByName(c.Param("provider"))
+
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Unknown provider"})
+		return
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	browser, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier))
+
+	if err != nil {
+		log.Println(err)
+		c.JSON(502, gin.H{"error": "Provider unavailable"})
+		return
+	}
+
+	oidcState := models.OIDCState{
+		StateHash:    auth.HashToken(state),
+		BrowserHash:  auth.HashToken(browser),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oidcStateLifetime),
+	}
+
+	if err := database.CreateOIDCState(db, &oidcState); err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	// the frontend calls the callback from another site, like the session and CSRF cookies this one must
+	// be sent cross-site
+	c.SetSameSite(http.SameSiteNoneMode)
+	c.SetCookie(oidcBrowserCookieName, browser, int(oidcStateLifetime.Seconds()), "/", "", true, true)
+
+	c.JSON(200, gin.H{"authorization_url": authURL})
+}
+
+func ReadOIDCProvidersHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{"providers": oidc.ProviderNames()})
+	}
+}
+
+func StartOIDCLoginHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startOIDC(c, db, nil)
+	}
+}
+
+// starts a login whose callback links the provider account to the logged in user
+func StartOIDCLinkHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		startOIDC(c, db, &userID)
+	}
+}
+
+// Handles the provider redirect. Depending on the stored state this links an identity, logs in the
+// user it belongs to, or hands back a signup token so the user can pick a username.
+func OIDCCallbackHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, err := oidc.ProviderByName(c.Param("provider"))
+
+		if err != nil {
+			c.JSON(404, gin.H{"error": "Unknown provider"})
+			return
+		}
+
+		if c.Query("error") != "" {
+			c.JSON(400, gin.H{"error": "Login cancelled or refused by provider"})
+			return
+		}
+
+		code := c.Query("code")
+		state := c.Query("state")
+
+		if code == "" || state == "" {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		// a state without the cookie of the browser that started it was handed to someone else
+		browser, err := c.Cookie(oidcBrowserCookieName)
+
+		if err != nil || browser == "" {
+			c.JSON(400, gin.H{"error": "Login expired, please sign in again"})
+			return
+		}
+
+		c.SetSameSite(http.SameSiteNoneMode)
+		c.SetCookie(oidcBrowserCookieName, "", -1, "/", "", true, true)
+
+		oidcState, err := database.ConsumeOIDCState(db, auth.HashToken(state), auth.HashToken(browser), provider.Name)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if oidcState == nil {
+			c.JSON(400, gin.H{"error": "Login expired, please sign in again"})
+			return
+		}
+
+		rawIDToken, err := provider.Exchange(code, oidcState.CodeVerifier)
+
+		if err != nil {
+			log.Println(err)
+			c.JSON(401, gin.H{"error": "Could not sign in with provider"})
+			return
+		}
+
+		claims, err := provider.Verify(rawIDToken, oidcState.Nonce)
+
+		if err != nil {
+			log.Println(err)
+			c.JSON(401, gin.H{"error": "Could not sign in with provider"})
+			return
+		}
+
+		identity, err := database.ReadIdentityByProviderSubject(db, provider.Name, claims.Subject)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if oidcState.LinkUserID != nil {
+			linkIdentity(c, db, *oidcState.LinkUserID, identity, provider.Name, claims)
+			return
+		}
+
+		if identity != nil {
+			user, err := database.ReadUserByID(db, identity.UserID)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if user == nil {
+				c.JSON(401, gin.H{"error": "Could not sign in with provider"})
+				return
+			}
+
+			attempt := models.LoginAttempt{
+				Username:  user.Username,
+				UserID:    &user.ID,
+				IP:        c.ClientIP(),
+				UserAgent: c.Request.UserAgent(),
+			}
+
+			completeLogin(c, db, user, &attempt, false)
+			return
+		}
+
+		signupToken, err := auth.GenerateSignupJWT(provider.Name, claims.Subject, claims.Email, claims.EmailVerified)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not generate token"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"signup_required":    true,
+			"signup_token":       signupToken,
+			"suggested_username": claims.PreferredUsername,
+			"email":              claims.Email,
+		})
+	}
+}
+
+func linkIdentity(c *gin.Context, db *sql.DB, userID int64, existing *models.Identity, provider string, claims *oidc.Claims) {
+	if existing != nil {
+		if existing.UserID == userID {
+			c.JSON(200, gin.H{"status": "Already linked", "id": existing.ID})
+			return
+		}
+		c.JSON(409, gin.H{"error": "This account is linked to another user"})
+		return
+	}
+
+	identity := models.Identity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	if err := database.CreateIdentity(db, &identity); err != nil {
+		if errors.Is(err, database.ErrDuplicateIdentity) {
+			c.JSON(409, gin.H{"error": "A " + provider + " account is already linked"})
+			return
+		}
+		c.JSON(500, gin.H{"error": "Could not link account"})
+		return
+	}
+
+	c.Set("audit_target_type", "identity")
+	c.Set("audit_target_id", identity.ID)
+
+	c.JSON(201, identity)
+}
+
+// creates the account for a first OIDC login, with the username the user picked
+func OIDCSignupHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.OIDCSignupInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Username == "" {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		provider, subject, email, emailVerified, err := auth.CheckSignupTokenValidity(input.SignupToken)
+
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Signup expired, please sign in again"})
+			return
+		}
+
+		ipBanned, err := database.IsIPBanned(db, c.ClientIP())
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if ipBanned {
+			c.JSON(403, gin.H{"error": "Registration not allowed"})
+			return
+		}
+
+		usernameBanned, err := database.IsUsernameBanned(db, input.Username)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if usernameBanned {
+			c.JSON(400, gin.H{"error": "Username not allowed"})
+			return
+		}
+
+		existing, err := database.ReadUserByUsername(db, input.Username)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if existing != nil {
+			c.JSON(409, gin.H{"error": "Username already taken"})
+			return
+		}
+
+		password, err := oidc.RandomString()
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		user := models.User{
+			Username: input.Username,
+			Password: password,
+		}
+
+		if email != "" && isValidEmail(email) {
+			user.Email = &email
+			user.EmailVerified = emailVerified
+		}
+
+		identity := models.Identity{
+			Provider: provider,
+			Subject:  subject,
+			Email:    email,
+		}
+
+		err = database.CreateUserWithIdentity(db, &user, &identity)
+
+		// the provider's email may already belong to another account; sign up without it
+		if errors.Is(err, database.ErrDuplicateEmail) {
+			user.Email = nil
+			user.EmailVerified = false
+			user.Password = password
+			err = database.CreateUserWithIdentity(db, &user, &identity)
+		}
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateIdentity) {
+				c.JSON(409, gin.H{"error": "This account is already registered"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not create user"})
+			return
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", user.ID)
+
+		attempt := models.LoginAttempt{
+			Username:  user.Username,
+			UserID:    &user.ID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+
+		completeLogin(c, db, &user, &attempt, false)
+	}
+}
+
+func ReadIdentityHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		identities, err := database.ReadIdentityByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":      len(identities),
+			"identities": identities,
+		})
+	}
+}
+
+func DeleteIdentityByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		strIdentityID := c.Param("identity_id")
+		identityID, err := strconv.ParseInt(strIdentityID, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		identityNotFound, err := database.DeleteIdentityByID(db, identityID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unlink account"})
+			return
+		}
+
+		if identityNotFound {
+			c.JSON(404, gin.H{"error": "Identity not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Account unlinked"})
+	}
+}
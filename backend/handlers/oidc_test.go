@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"backend/oidc/mock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sends the user to the mock provider and returns the code and state it redirects back with
+func authorizeWithMock(t *testing.T, authorizationURL string, subject string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authorizationURL + "&sub=" + url.QueryEscape(subject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", res.StatusCode)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+// starts a login and returns the provider URL along with the cookie binding it to this browser
+func startMockLogin(t *testing.T, router *gin.Engine) (string, *http.Cookie) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/auth/oidc/mock/login", nil))
+
+	if recorder.Code != 200 {
+		t.Fatalf("login start returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	var browser *http.Cookie
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == oidcBrowserCookieName {
+			browser = cookie
+		}
+	}
+
+	if browser == nil || !browser.HttpOnly || !browser.Secure || browser.SameSite != http.SameSiteNoneMode {
+		t.Fatalf("browser cookie %+v, want HttpOnly, Secure and SameSite=None", browser)
+	}
+
+	authorizationURL, _ := decodeBody(t, recorder)["authorization_url"].(string)
+
+	return authorizationURL, browser
+}
+
+func callback(router *gin.Engine, code string, state string, browser *http.Cookie) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", "/auth/oidc/mock/callback?code="+url.QueryEscape(code)+"&state="+url.QueryEscape(state), nil)
+	if browser != nil {
+		request.AddCookie(browser)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestOIDCRoundTrip(t *testing.T) {
+	db := testDB(t)
+
+	var provider http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var err error
+	if provider, err = mock.NewHandler(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	// providers are loaded on first use, which is this test
+	os.Setenv("OIDC_PROVIDERS", "mock")
+	os.Setenv("OIDC_MOCK_ISSUER", server.URL)
+	os.Setenv("OIDC_MOCK_CLIENT_ID", "chatit")
+	os.Setenv("OIDC_MOCK_REDIRECT_URL", "http://localhost:3000/oidc/callback")
+
+	router := gin.New()
+	router.GET("/auth/oidc/:provider/login", StartOIDCLoginHandler(db))
+	router.GET("/auth/oidc/:provider/callback", OIDCCallbackHandler(db))
+	router.POST("/auth/oidc/signup", OIDCSignupHandler(db))
+
+	subject := testName("subject-")
+
+	// a callback carried to another browser is refused, and the state stays usable by its own
+	authorizationURL, browser := startMockLogin(t, router)
+	code, state := authorizeWithMock(t, authorizationURL, subject)
+
+	if recorder := callback(router, code, state, nil); recorder.Code != 400 {
+		t.Fatalf("callback without the browser cookie returned %d", recorder.Code)
+	}
+
+	if recorder := callback(router, code, state, &http.Cookie{Name: oidcBrowserCookieName, Value: "someone-else"}); recorder.Code != 400 {
+		t.Fatalf("callback with another browser's cookie returned %d", recorder.Code)
+	}
+
+	recorder := callback(router, code, state, browser)
+	if recorder.Code != 200 {
+		t.Fatalf("callback returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	signupToken, _ := decodeBody(t, recorder)["signup_token"].(string)
+	if signupToken == "" {
+		t.Fatalf("unknown identity got no signup token: %s", recorder.Body.String())
+	}
+
+	// the state works only once
+	if recorder := callback(router, code, state, browser); recorder.Code != 400 {
+		t.Fatalf("replayed callback returned %d", recorder.Code)
+	}
+
+	username := testName("oidc_")
+	signup := httptest.NewRequest("POST", "/auth/oidc/signup", strings.NewReader(`{"signup_token": "`+signupToken+`", "username": "`+username+`"}`))
+	signup.Header.Set("Content-Type", "application/json")
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, signup)
+
+	if recorder.Code != 200 {
+		t.Fatalf("signup returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	userID, _ := decodeBody(t, recorder)["user_id"].(float64)
+
+	// signing in again with the same provider account logs into the new user
+	authorizationURL, browser = startMockLogin(t, router)
+	code, state = authorizeWithMock(t, authorizationURL, subject)
+
+	recorder = callback(router, code, state, browser)
+	if recorder.Code != 200 {
+		t.Fatalf("second callback returned %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	if loggedIn, _ := decodeBody(t, recorder)["user_id"].(float64); loggedIn == 0 || loggedIn != userID {
+		t.Fatalf("logged in as %v, want %v", loggedIn, userID)
+	}
+}
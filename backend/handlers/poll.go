@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limits on polls
+const (
+	maxPollOptions        = 10
+	maxPollQuestionLength = 300
+	maxPollOptionLength   = 200
+)
+
+// validates a poll submitted with a new post, returning the poll or why it was refused
+func pollFromInput(input *models.CreatePollInput) (*models.Poll, string) {
+	question := strings.TrimSpace(input.Question)
+
+	if question == "" || utf8.RuneCountInString(question) > maxPollQuestionLength {
+		return nil, "Poll question must be between 1 and " + strconv.Itoa(maxPollQuestionLength) + " characters"
+	}
+
+	if len(input.Options) < 2 || len(input.Options) > maxPollOptions {
+		return nil, "A poll needs between 2 and " + strconv.Itoa(maxPollOptions) + " options"
+	}
+
+	if input.ClosesAt != nil && !input.ClosesAt.After(time.Now()) {
+		return nil, "Poll close time must be in the future"
+	}
+
+	poll := models.Poll{
+		Question:       question,
+		MultipleChoice: input.MultipleChoice,
+		PublicVotes:    input.PublicVotes,
+		HideResults:    input.HideResults,
+		ClosesAt:       input.ClosesAt,
+	}
+
+	seen := map[string]bool{}
+
+	for _, text := range input.Options {
+		text = strings.TrimSpace(text)
+
+		if text == "" || utf8.RuneCountInString(text) > maxPollOptionLength {
+			return nil, "Poll options must be between 1 and " + strconv.Itoa(maxPollOptionLength) + " characters"
+		}
+
+		if seen[strings.ToLower(text)] {
+			return nil, "Poll options must be different"
+		}
+		seen[strings.ToLower(text)] = true
+
+		poll.Options = append(poll.Options, models.PollOption{Text: text})
+	}
+
+	return &poll, ""
+}
+
+// the poll's text, so the content filter sees it along with the post
+func pollText(poll *models.Poll) string {
+	if poll == nil {
+		return ""
+	}
+
+	parts := []string{poll.Question}
+	for _, option := range poll.Options {
+		parts = append(parts, option.Text)
+	}
+
+	return "\n" + strings.Join(parts, "\n")
+}
+
+// Blanks the counts, voter total and voters of a poll that hides its results, unless the viewer has voted,
+// wrote the post, or the poll has closed.
+func preparePoll(poll *models.Poll, viewerID int64, postAuthor int64) {
+	if !poll.HideResults || poll.IsClosed || len(poll.MyVotes) > 0 || (viewerID != 0 && viewerID == postAuthor) {
+		return
+	}
+
+	poll.ResultsHidden = true
+	poll.TotalVoters = 0
+
+	for i := range poll.Options {
+		poll.Options[i].Votes = 0
+		poll.Options[i].Voters = nil
+	}
+}
+
+// Reads the post's poll for a vote, answering the request when the poll can't take one. The returned
+// post is needed to prepare the poll for the response afterwards.
+func readOpenPoll(c *gin.Context, db *sql.DB, userID int64) (*models.Post, *models.Poll, bool) {
+	postIDStr := c.Param("post_id")
+	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	if err != nil || postID <= 0 {
+		c.JSON(400, gin.H{"error": "Invalid post ID"})
+		return nil, nil, false
+	}
+
+	post, err := database.ReadPostByID(db, postID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return nil, nil, false
+	}
+
+	if post == nil {
+		c.JSON(404, gin.H{"error": "Post not found"})
+		return nil, nil, false
+	}
+
+	poll, err := database.ReadPollByPostID(db, postID, userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return nil, nil, false
+	}
+
+	if poll == nil {
+		c.JSON(404, gin.H{"error": "Poll not found"})
+		return nil, nil, false
+	}
+
+	if poll.IsClosed {
+		c.JSON(409, gin.H{"error": "Poll is closed"})
+		return nil, nil, false
+	}
+
+	blocked, err := database.IsBlockedBy(db, post.CreatedBy, userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return nil, nil, false
+	}
+
+	if blocked {
+		c.JSON(403, gin.H{"error": "You cannot vote on this user's poll"})
+		return nil, nil, false
+	}
+
+	return post, poll, true
+}
+
+// binds the chosen options, answering the request when they don't make a valid vote
+func bindPollVote(c *gin.Context, poll *models.Poll) ([]int64, bool) {
+	var input models.PollVoteInput
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.OptionIDs) == 0 {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return nil, false
+	}
+
+	if !poll.MultipleChoice && len(input.OptionIDs) > 1 {
+		c.JSON(400, gin.H{"error": "This poll allows one choice"})
+		return nil, false
+	}
+
+	seen := map[int64]bool{}
+	for _, optionID := range input.OptionIDs {
+		if seen[optionID] {
+			c.JSON(400, gin.H{"error": "Options must be different"})
+			return nil, false
+		}
+		seen[optionID] = true
+	}
+
+	return input.OptionIDs, true
+}
+
+// answers with the poll as the voter now sees it
+func respondWithPoll(c *gin.Context, db *sql.DB, status int, post *models.Post, userID int64) {
+	poll, err := database.ReadPollByPostID(db, post.ID, userID)
+
+	if err != nil || poll == nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	preparePoll(poll, userID, post.CreatedBy)
+
+	c.JSON(status, poll)
+}
+
+func ReadPollByPostIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid post ID"})
+			return
+		}
+
+		post, err := database.ReadPostByID(db, postID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if post == nil {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		poll, err := database.ReadPollByPostID(db, postID, viewerID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if poll == nil {
+			c.JSON(404, gin.H{"error": "Poll not found"})
+			return
+		}
+
+		preparePoll(poll, viewerID, post.CreatedBy)
+
+		c.JSON(200, poll)
+	}
+}
+
+func CreatePollVoteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		post, poll, ok := readOpenPoll(c, db, userID)
+		if !ok {
+			return
+		}
+
+		optionIDs, ok := bindPollVote(c, poll)
+		if !ok {
+			return
+		}
+
+		err := database.CreatePollVote(db, poll.ID, userID, optionIDs)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicatePollVote) {
+				c.JSON(409, gin.H{"error": "User has already voted on this poll"})
+				return
+			}
+			if errors.Is(err, database.ErrInvalidPollOption) {
+				c.JSON(400, gin.H{"error": "Option not found on this poll"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not record vote"})
+			return
+		}
+
+		respondWithPoll(c, db, 201, post, userID)
+	}
+}
+
+func UpdatePollVoteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		post, poll, ok := readOpenPoll(c, db, userID)
+		if !ok {
+			return
+		}
+
+		optionIDs, ok := bindPollVote(c, poll)
+		if !ok {
+			return
+		}
+
+		vote_not_found, err := database.UpdatePollVote(db, poll.ID, userID, optionIDs)
+
+		if err != nil {
+			if errors.Is(err, database.ErrInvalidPollOption) {
+				c.JSON(400, gin.H{"error": "Option not found on this poll"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not change vote"})
+			return
+		}
+
+		if vote_not_found {
+			c.JSON(404, gin.H{"error": "Vote not found"})
+			return
+		}
+
+		respondWithPoll(c, db, 200, post, userID)
+	}
+}
+
+func DeletePollVoteHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		post, poll, ok := readOpenPoll(c, db, userID)
+		if !ok {
+			return
+		}
+
+		vote_not_found, err := database.DeletePollVote(db, poll.ID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not retract vote"})
+			return
+		}
+
+		if vote_not_found {
+			c.JSON(404, gin.H{"error": "Vote not found"})
+			return
+		}
+
+		respondWithPoll(c, db, 200, post, userID)
+	}
+}
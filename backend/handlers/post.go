@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"backend/contentfilter"
 	"backend/database"
 	"backend/models"
+	"backend/utils"
 	"database/sql"
 	"errors"
+	"log"
 	"strings"
 
 	"strconv"
@@ -28,16 +31,65 @@ func CreatePostHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		if input.Title == "" || input.Description == "" || input.CreatedBy <= 0 {
+		if input.Title == "" || input.Description == "" {
 			c.JSON(400, gin.H{"error": "empty fields"})
 			return
 		}
 
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		blocked, err := database.IsBlockedByAnyUsername(db, utils.ExtractMentions(input.Title+" "+input.Description), userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot mention this user"})
+			return
+		}
+
+		var poll *models.Poll
+
+		if input.Poll != nil {
+			var reason string
+
+			if poll, reason = pollFromInput(input.Poll); poll == nil {
+				c.JSON(400, gin.H{"error": reason})
+				return
+			}
+		}
+
 		post := models.Post{
 			Title:       input.Title,
 			Description: input.Description,
 			TopicID:     topicID,
-			CreatedBy:   input.CreatedBy,
+			CreatedBy:   userID,
+			Poll:        poll,
+		}
+
+		content := contentfilter.Content{
+			Kind:   models.TargetTypePost,
+			UserID: userID,
+			Title:  input.Title,
+			Body:   input.Description + pollText(poll),
+		}
+
+		if !applyContentFilter(c, db, &content, post) {
+			return
 		}
 
 		if err := database.CreatePost(db, &post); err != nil {
@@ -45,6 +97,20 @@ func CreatePostHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		c.Set("audit_target_type", "post")
+		c.Set("audit_target_id", post.ID)
+
+		// authors watch their own posts by default
+		watch := models.PostWatch{
+			PostID: post.ID,
+			UserID: post.CreatedBy,
+			Level:  models.WatchLevelAll,
+		}
+
+		if err := database.UpsertPostWatch(db, &watch); err != nil {
+			log.Println(err)
+		}
+
 		c.JSON(201, gin.H{
 			"id":          post.ID,
 			"title":       post.Title,
@@ -57,6 +123,7 @@ func CreatePostHandler(db *sql.DB) gin.HandlerFunc {
 			"popularity":  post.Popularity,
 			"created_by":  post.CreatedBy,
 			"created_at":  post.CreatedAt,
+			"poll":        post.Poll,
 		})
 	}
 }
@@ -83,18 +150,37 @@ func ReadPostByIDHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		poll, err := database.ReadPollByPostID(db, post.ID, viewerID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if poll != nil {
+			preparePoll(poll, viewerID, post.CreatedBy)
+		}
+
 		c.JSON(200, gin.H{
-			"id":          post.ID,
-			"title":       post.Title,
-			"description": post.Description,
-			"topic_id":    post.TopicID,
-			"likes":       post.Likes,
-			"dislikes":    post.Dislikes,
-			"is_edited":   post.IsEdited,
-			"views":       post.Views,
-			"popularity":  post.Popularity,
-			"created_by":  post.CreatedBy,
-			"created_at":  post.CreatedAt,
+			"id":                  post.ID,
+			"title":               post.Title,
+			"description":         post.Description,
+			"topic_id":            post.TopicID,
+			"likes":               post.Likes,
+			"dislikes":            post.Dislikes,
+			"is_edited":           post.IsEdited,
+			"views":               post.Views,
+			"popularity":          post.Popularity,
+			"created_by":          post.CreatedBy,
+			"created_at":          post.CreatedAt,
+			"accepted_comment_id": post.AcceptedCommentID,
+			"is_answered":         post.IsAnswered,
+			"poll":                poll,
 		})
 	}
 }
@@ -264,7 +350,14 @@ func ReadPostByTopicIDHandler(db *sql.DB) gin.HandlerFunc {
 			order = "DESC"
 		}
 
-		postsData, err := database.ReadPostByTopicID(db, topicID, limit, offset, sortBy, order)
+		unanswered := c.Query("unanswered") == "true"
+
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		postsData, err := database.ReadPostByTopicID(db, topicID, viewerID, limit, offset, sortBy, order, unanswered)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
@@ -350,7 +443,12 @@ func ReadPostBySearchQueryHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		postsData, err := database.ReadPostBySearchQuery(db, topicID, limit, offset, sortBy, order, searchQuery)
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		postsData, err := database.ReadPostBySearchQuery(db, topicID, viewerID, limit, offset, sortBy, order, searchQuery)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
@@ -410,12 +508,40 @@ func CreatePostReactionHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		post, err := database.ReadPostByID(db, postID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if post == nil {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		blocked, err := database.IsBlockedBy(db, post.CreatedBy, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if blocked {
+			c.JSON(403, gin.H{"error": "You cannot react to this user"})
+			return
+		}
+
 		var input models.CreatePostReactionInput
 		if err := c.ShouldBindJSON(&input); err != nil {
 			c.JSON(400, gin.H{"error": "Invalid payload"})
 			return
 		}
 
+		if !input.Reaction && !checkPrivilege(c, db, userID, models.PrivilegeDislike) {
+			return
+		}
+
 		postReaction := models.PostReaction{
 			PostID:   postID,
 			UserID:   userID,
@@ -553,7 +679,12 @@ func ReadPostHandler(db *sql.DB) gin.HandlerFunc {
 			order = "DESC"
 		}
 
-		postsData, err := database.ReadPost(db, limit, offset, sortBy, order)
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		postsData, err := database.ReadPost(db, viewerID, limit, offset, sortBy, order)
 
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func isValidReportTargetType(targetType string) bool {
+	return targetType == models.TargetTypePost || targetType == models.TargetTypeComment ||
+		targetType == models.TargetTypeTopic || targetType == models.TargetTypeUser
+}
+
+func isValidReportReasonCode(reasonCode string) bool {
+	for _, code := range models.ReportReasonCodes {
+		if reasonCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func CreateReportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.CreateReportInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if !isValidReportTargetType(input.TargetType) || input.TargetID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid target"})
+			return
+		}
+
+		if !isValidReportReasonCode(input.ReasonCode) {
+			c.JSON(400, gin.H{"error": "Invalid reason code"})
+			return
+		}
+
+		if len(input.Details) > 2000 {
+			c.JSON(400, gin.H{"error": "Details too long"})
+			return
+		}
+
+		_, found, err := database.ReadReportTargetOwner(db, input.TargetType, input.TargetID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !found {
+			c.JSON(404, gin.H{"error": "Resource not found"})
+			return
+		}
+
+		report := models.Report{
+			ReporterID: userID,
+			TargetType: input.TargetType,
+			TargetID:   input.TargetID,
+			ReasonCode: input.ReasonCode,
+			Details:    input.Details,
+		}
+
+		err = database.CreateReport(db, &report)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateReport) {
+				c.JSON(409, gin.H{"error": "User has already reported this"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not create report"})
+			return
+		}
+
+		c.JSON(201, report)
+	}
+}
+
+func ReadReportQueueHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		targetType := c.DefaultQuery("target_type", "")
+		if targetType != "" && !isValidReportTargetType(targetType) {
+			c.JSON(400, gin.H{"error": "Invalid target type"})
+			return
+		}
+
+		groupsData, err := database.ReadOpenReportGroup(db, targetType, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(groupsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":   0,
+				"page":    page,
+				"limit":   limit,
+				"reports": []models.ReportGroup{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":   len(groupsData),
+			"page":    page,
+			"limit":   limit,
+			"reports": groupsData,
+		})
+	}
+}
+
+func ReadReportByTargetHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetType := c.Param("target_type")
+		targetID, err := strconv.ParseInt(c.Param("target_id"), 10, 64)
+
+		if err != nil || targetID <= 0 || !isValidReportTargetType(targetType) {
+			c.JSON(400, gin.H{"error": "Invalid target"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		reportsData, err := database.ReadReportByTarget(db, targetType, targetID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(reportsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":   0,
+				"page":    page,
+				"limit":   limit,
+				"reports": []models.Report{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":   len(reportsData),
+			"page":    page,
+			"limit":   limit,
+			"reports": reportsData,
+		})
+	}
+}
+
+// applies a moderator action to a reported target and resolves all of its open reports
+func ResolveReportHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		targetType := c.Param("target_type")
+		targetID, err := strconv.ParseInt(c.Param("target_id"), 10, 64)
+
+		if err != nil || targetID <= 0 || !isValidReportTargetType(targetType) {
+			c.JSON(400, gin.H{"error": "Invalid target"})
+			return
+		}
+
+		var input models.ModerationActionInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		moderatorID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		ownerID, found, err := database.ReadReportTargetOwner(db, targetType, targetID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// dismissing is still allowed when the target has since disappeared
+		if !found && input.Action != models.ModerationActionDismiss {
+			c.JSON(404, gin.H{"error": "Resource not found"})
+			return
+		}
+
+		switch input.Action {
+		case models.ModerationActionDismiss:
+
+		case models.ModerationActionRemove:
+			var not_found bool
+			switch targetType {
+			case models.TargetTypePost:
+				not_found, err = database.DeletePostByID(db, targetID)
+			case models.TargetTypeComment:
+				not_found, err = database.DeleteCommentByID(db, targetID)
+			case models.TargetTypeTopic:
+				not_found, err = database.DeleteTopicByID(db, targetID)
+			default:
+				c.JSON(400, gin.H{"error": "Users cannot be removed, suspend them instead"})
+				return
+			}
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Could not remove content"})
+				return
+			}
+
+			if not_found {
+				c.JSON(404, gin.H{"error": "Resource not found"})
+				return
+			}
+
+		case models.ModerationActionWarn, models.ModerationActionSuspend:
+			// content owned by the system user has no author left to sanction
+			if ownerID == 0 {
+				c.JSON(400, gin.H{"error": "Author no longer exists"})
+				return
+			}
+
+			if input.Action == models.ModerationActionWarn {
+				warning := models.Warning{
+					UserID:    ownerID,
+					Reason:    input.Note,
+					CreatedBy: moderatorID,
+				}
+
+				if err := database.CreateWarning(db, &warning); err != nil {
+					c.JSON(500, gin.H{"error": "Could not warn user"})
+					return
+				}
+			} else {
+				if input.SuspendDays <= 0 {
+					input.SuspendDays = 7
+				}
+				expiresAt := time.Now().AddDate(0, 0, input.SuspendDays)
+
+				suspension := models.Suspension{
+					UserID:    ownerID,
+					Reason:    input.Note,
+					ExpiresAt: &expiresAt,
+					CreatedBy: moderatorID,
+				}
+
+				if err := database.CreateSuspension(db, &suspension); err != nil {
+					c.JSON(500, gin.H{"error": "Could not suspend user"})
+					return
+				}
+			}
+
+		default:
+			c.JSON(400, gin.H{"error": "Invalid action"})
+			return
+		}
+
+		resolved, err := database.ResolveReportByTarget(db, targetType, targetID, input.Action, input.Note, moderatorID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not resolve reports"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"status":   "Reports resolved",
+			"action":   input.Action,
+			"resolved": resolved,
+		})
+	}
+}
+
+func UpdateUserRoleByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.UpdateUserRoleInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Role != models.RoleUser && input.Role != models.RoleModerator && input.Role != models.RoleAdmin {
+			c.JSON(400, gin.H{"error": "Invalid role"})
+			return
+		}
+
+		user_not_found, err := database.UpdateUserRoleByID(db, id, input.Role)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not update role"})
+			return
+		}
+
+		if user_not_found {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Updated successfully"})
+	}
+}
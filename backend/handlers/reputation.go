@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"os"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the largest penalty a moderator can give in one go
+const maxReputationPenalty = 1000
+
+// Reputation needed for a privilege: REPUTATION_CREATE_TOPIC (default 20) and REPUTATION_DISLIKE
+// (default 15). 0 gives the privilege to everyone.
+func reputationThreshold(privilege string) int {
+	name, fallback := "REPUTATION_CREATE_TOPIC", 20
+	if privilege == models.PrivilegeDislike {
+		name, fallback = "REPUTATION_DISLIKE", 15
+	}
+
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+
+	return fallback
+}
+
+// checks that the user has earned a privilege, answering the request otherwise
+func checkPrivilege(c *gin.Context, db *sql.DB, userID int64, privilege string) bool {
+	threshold := reputationThreshold(privilege)
+
+	if threshold == 0 {
+		return true
+	}
+
+	role, err := database.ReadUserRoleByID(db, userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	if role == models.RoleModerator || role == models.RoleAdmin {
+		return true
+	}
+
+	reputation, err := database.ReadReputationByUserID(db, userID)
+
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	if reputation < threshold {
+		c.JSON(403, gin.H{
+			"error":      "Not enough reputation",
+			"privilege":  privilege,
+			"required":   threshold,
+			"reputation": reputation,
+		})
+		return false
+	}
+
+	return true
+}
+
+func ReadReputationByUserIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || userID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		if !checkActivityVisible(c, db, userID) {
+			return
+		}
+
+		reputation, err := database.ReadReputationByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		events, err := database.ReadReputationEventByUserID(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"user_id":    userID,
+			"reputation": reputation,
+			"count":      len(events),
+			"page":       page,
+			"limit":      limit,
+			"events":     events,
+		})
+	}
+}
+
+// takes reputation away from a user, recorded in their history with the moderator's note
+func CreateReputationPenaltyHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		targetUserID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || targetUserID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.CreateReputationPenaltyInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Note == "" {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		if input.Points <= 0 || input.Points > maxReputationPenalty {
+			c.JSON(400, gin.H{"error": "Points must be between 1 and " + strconv.Itoa(maxReputationPenalty)})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		moderatorID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if moderatorID == targetUserID {
+			c.JSON(400, gin.H{"error": "Cannot penalise yourself"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, targetUserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if username == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		event := models.ReputationEvent{
+			UserID:     targetUserID,
+			Points:     -input.Points,
+			Reason:     models.ReputationPenalty,
+			SourceType: models.TargetTypeUser,
+			SourceID:   &targetUserID,
+			Note:       input.Note,
+		}
+
+		if err := database.CreateReputationEvent(db, &event, &moderatorID); err != nil {
+			c.JSON(500, gin.H{"error": "Could not apply penalty"})
+			return
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", targetUserID)
+
+		c.JSON(201, event)
+	}
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+	"net"
+	"regexp"
+
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moderators may suspend temporarily, only admins may ban permanently or suspend other admins
+func CreateSuspensionHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		targetUserID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || targetUserID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.CreateSuspensionInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Reason == "" {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		moderatorID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		if moderatorID == targetUserID {
+			c.JSON(400, gin.H{"error": "Cannot suspend yourself"})
+			return
+		}
+
+		if input.Permanent && c.GetString("role") != models.RoleAdmin {
+			c.JSON(403, gin.H{"error": "Only admins can ban permanently"})
+			return
+		}
+
+		targetRole, err := database.ReadUserRoleByID(db, targetUserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if targetRole == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		if targetRole == models.RoleAdmin && c.GetString("role") != models.RoleAdmin {
+			c.JSON(403, gin.H{"error": "Only admins can suspend admins"})
+			return
+		}
+
+		suspension := models.Suspension{
+			UserID:    targetUserID,
+			Reason:    input.Reason,
+			CreatedBy: moderatorID,
+		}
+
+		if !input.Permanent {
+			if input.Days <= 0 {
+				input.Days = 7
+			}
+			expiresAt := time.Now().AddDate(0, 0, input.Days)
+			suspension.ExpiresAt = &expiresAt
+		}
+
+		if err := database.CreateSuspension(db, &suspension); err != nil {
+			c.JSON(500, gin.H{"error": "Could not suspend user"})
+			return
+		}
+
+		c.JSON(201, suspension)
+	}
+}
+
+func ReadSuspensionByUserIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		targetUserID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil || targetUserID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		suspensionsData, err := database.ReadSuspensionByUserID(db, targetUserID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(suspensionsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":       0,
+				"page":        page,
+				"limit":       limit,
+				"suspensions": []models.Suspension{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":       len(suspensionsData),
+			"page":        page,
+			"limit":       limit,
+			"suspensions": suspensionsData,
+		})
+	}
+}
+
+func LiftSuspensionByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("suspension_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		suspension, err := database.ReadSuspensionByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if suspension == nil {
+			c.JSON(404, gin.H{"error": "Suspension not found"})
+			return
+		}
+
+		// a ban can only be undone by those who may issue one
+		if suspension.ExpiresAt == nil && c.GetString("role") != models.RoleAdmin {
+			c.JSON(403, gin.H{"error": "Only admins can lift permanent bans"})
+			return
+		}
+
+		suspension_not_found, err := database.LiftSuspensionByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not lift suspension"})
+			return
+		}
+
+		if suspension_not_found {
+			c.JSON(404, gin.H{"error": "Suspension not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Suspension lifted"})
+	}
+}
+
+func CreateBannedIPHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.CreateBannedIPInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		// single addresses are accepted as well as ranges
+		if _, _, err := net.ParseCIDR(input.CIDR); err != nil && net.ParseIP(input.CIDR) == nil {
+			c.JSON(400, gin.H{"error": "Invalid IP or CIDR range"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		bannedIP := models.BannedIP{
+			CIDR:      input.CIDR,
+			Reason:    input.Reason,
+			CreatedBy: userID,
+		}
+
+		err := database.CreateBannedIP(db, &bannedIP)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateBannedIP) {
+				c.JSON(409, gin.H{"error": "IP range is already banned"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not ban IP range"})
+			return
+		}
+
+		c.Set("audit_target_type", "banned_ip")
+		c.Set("audit_target_id", bannedIP.ID)
+
+		c.JSON(201, bannedIP)
+	}
+}
+
+func ReadBannedIPHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		bannedIPsData, err := database.ReadBannedIP(db, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(bannedIPsData) == 0 {
+			c.JSON(200, gin.H{
+				"count":      0,
+				"page":       page,
+				"limit":      limit,
+				"banned_ips": []models.BannedIP{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":      len(bannedIPsData),
+			"page":       page,
+			"limit":      limit,
+			"banned_ips": bannedIPsData,
+		})
+	}
+}
+
+func DeleteBannedIPByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("banned_ip_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		banned_ip_not_found, err := database.DeleteBannedIPByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unban IP range"})
+			return
+		}
+
+		if banned_ip_not_found {
+			c.JSON(404, gin.H{"error": "Banned IP range not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "IP range unbanned"})
+	}
+}
+
+func CreateBannedUsernameHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.CreateBannedUsernameInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		if input.Pattern == "" {
+			c.JSON(400, gin.H{"error": "empty fields"})
+			return
+		}
+
+		if _, err := regexp.Compile(input.Pattern); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid pattern"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		bannedUsername := models.BannedUsername{
+			Pattern:   input.Pattern,
+			Reason:    input.Reason,
+			CreatedBy: userID,
+		}
+
+		err := database.CreateBannedUsername(db, &bannedUsername)
+
+		if err != nil {
+			if errors.Is(err, database.ErrDuplicateBannedUsername) {
+				c.JSON(409, gin.H{"error": "Pattern is already banned"})
+				return
+			}
+			c.JSON(500, gin.H{"error": "Could not ban username pattern"})
+			return
+		}
+
+		c.Set("audit_target_type", "banned_username")
+		c.Set("audit_target_id", bannedUsername.ID)
+
+		c.JSON(201, bannedUsername)
+	}
+}
+
+func ReadBannedUsernameHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		bannedUsernamesData, err := database.ReadBannedUsername(db, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(bannedUsernamesData) == 0 {
+			c.JSON(200, gin.H{
+				"count":            0,
+				"page":             page,
+				"limit":            limit,
+				"banned_usernames": []models.BannedUsername{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count":            len(bannedUsernamesData),
+			"page":             page,
+			"limit":            limit,
+			"banned_usernames": bannedUsernamesData,
+		})
+	}
+}
+
+func DeleteBannedUsernameByIDHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("banned_username_id")
+		id, err := strconv.ParseInt(strid, 10, 64)
+
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		banned_username_not_found, err := database.DeleteBannedUsernameByID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unban username pattern"})
+			return
+		}
+
+		if banned_username_not_found {
+			c.JSON(404, gin.H{"error": "Banned username pattern not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Username pattern unbanned"})
+	}
+}
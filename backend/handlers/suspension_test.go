@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"backend/models"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func suspensionRouter(user models.User) *gin.Engine {
+	router := routerAs(user)
+	router.POST("/users/:user_id/suspensions", CreateSuspensionHandler(testDBConn))
+	router.DELETE("/suspensions/:suspension_id", LiftSuspensionByIDHandler(testDBConn))
+
+	return router
+}
+
+func TestSuspensionRoles(t *testing.T) {
+	db := testDB(t)
+
+	admin := testUser(t, db, models.RoleAdmin)
+	moderator := testUser(t, db, models.RoleModerator)
+	member := testUser(t, db, "")
+
+	asAdmin := suspensionRouter(admin)
+	asModerator := suspensionRouter(moderator)
+
+	suspend := func(router *gin.Engine, userID int64, body string) (int, int64) {
+		recorder := serveJSON(router, "POST", "/users/"+strconv.FormatInt(userID, 10)+"/suspensions", body)
+		if recorder.Code != 201 {
+			return recorder.Code, 0
+		}
+
+		id, _ := decodeBody(t, recorder)["id"].(float64)
+		return recorder.Code, int64(id)
+	}
+
+	lift := func(router *gin.Engine, id int64) int {
+		return serveJSON(router, "DELETE", "/suspensions/"+strconv.FormatInt(id, 10), "").Code
+	}
+
+	if code, _ := suspend(asModerator, admin.ID, `{"reason": "spam", "days": 1}`); code != 403 {
+		t.Fatalf("moderator suspending an admin got %d, want 403", code)
+	}
+
+	if code, _ := suspend(asModerator, member.ID, `{"reason": "spam", "permanent": true}`); code != 403 {
+		t.Fatalf("moderator banning got %d, want 403", code)
+	}
+
+	code, ban := suspend(asAdmin, member.ID, `{"reason": "spam", "permanent": true}`)
+	if code != 201 {
+		t.Fatalf("admin banning got %d", code)
+	}
+
+	if code := lift(asModerator, ban); code != 403 {
+		t.Fatalf("moderator lifting a ban got %d, want 403", code)
+	}
+
+	if code := lift(asAdmin, ban); code != 200 {
+		t.Fatalf("admin lifting a ban got %d", code)
+	}
+
+	code, suspension := suspend(asModerator, member.ID, `{"reason": "spam", "days": 1}`)
+	if code != 201 {
+		t.Fatalf("moderator suspending a member got %d", code)
+	}
+
+	if code := lift(asModerator, suspension); code != 200 {
+		t.Fatalf("moderator lifting a suspension got %d", code)
+	}
+}
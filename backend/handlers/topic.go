@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/contentfilter"
 	"backend/database"
 	"backend/models"
 	"database/sql"
@@ -38,10 +39,26 @@ func CreateTopicHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		if !checkPrivilege(c, db, userID, models.PrivilegeCreateTopic) {
+			return
+		}
+
 		topic := models.Topic{
 			Title:       input.Title,
 			Description: input.Description,
 			CreatedBy:   userID,
+			QAMode:      input.QAMode,
+		}
+
+		content := contentfilter.Content{
+			Kind:   models.TargetTypeTopic,
+			UserID: userID,
+			Title:  input.Title,
+			Body:   input.Description,
+		}
+
+		if !applyContentFilter(c, db, &content, topic) {
+			return
 		}
 
 		if err := database.CreateTopic(db, &topic); err != nil {
@@ -49,12 +66,16 @@ func CreateTopicHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		c.Set("audit_target_type", "topic")
+		c.Set("audit_target_id", topic.ID)
+
 		c.JSON(201, gin.H{
 			"id":          topic.ID,
 			"title":       topic.Title,
 			"description": topic.Description,
 			"created_by":  topic.CreatedBy,
 			"created_at":  topic.CreatedAt,
+			"qa_mode":     topic.QAMode,
 		})
 	}
 }
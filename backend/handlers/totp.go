@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"math"
+
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const recoveryCodeCount = 10
+
+// accepts either a current TOTP code or an unused recovery code, consuming it
+func verifySecondFactor(db *sql.DB, userID int64, code string) (bool, error) {
+	secret, enabled, lastStep, err := database.ReadTOTPByUserID(db, userID)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	if step, valid := auth.ValidateTOTP(secret, code, lastStep); valid {
+		return database.UseTOTPStep(db, userID, step)
+	}
+
+	return database.UseRecoveryCode(db, userID, auth.HashRecoveryCode(code))
+}
+
+// generates recovery codes, returning them in plain text once and their hashes for storage
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+
+	return codes, hashes, nil
+}
+
+// starts enrollment with a fresh secret; 2FA stays off until a code from it is verified
+func EnrollTOTPHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		_, enabled, _, err := database.ReadTOTPByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if enabled {
+			c.JSON(409, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+
+		username, err := database.ReadUsernameByID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if username == "" {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		secret, err := auth.GenerateTOTPSecret()
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err := database.UpdateTOTPSecretByUserID(db, userID, secret); err != nil {
+			c.JSON(500, gin.H{"error": "Could not start enrollment"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"secret":      secret,
+			"otpauth_uri": auth.TOTPURI(secret, username),
+		})
+	}
+}
+
+func ActivateTOTPHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.TOTPCodeInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		secret, enabled, _, err := database.ReadTOTPByUserID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if enabled {
+			c.JSON(409, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+
+		if secret == "" {
+			c.JSON(400, gin.H{"error": "Two-factor enrollment has not been started"})
+			return
+		}
+
+		step, valid := auth.ValidateTOTP(secret, input.Code, 0)
+
+		if !valid {
+			c.JSON(401, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		codes, hashes, err := newRecoveryCodes()
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err := database.EnableTOTPByUserID(db, userID, step, hashes); err != nil {
+			c.JSON(500, gin.H{"error": "Could not enable two-factor authentication"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"status":         "Two-factor authentication enabled",
+			"recovery_codes": codes,
+		})
+	}
+}
+
+// requires both the password and a second factor, so a stolen session alone can't turn 2FA off
+func DisableTOTPHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.DisableTOTPInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		user, err := database.ReadUserByID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if user == nil {
+			c.JSON(404, gin.H{"error": "User not found"})
+			return
+		}
+
+		if !user.TOTPEnabled {
+			c.JSON(400, gin.H{"error": "Two-factor authentication is not enabled"})
+			return
+		}
+
+		if _, err := auth.CheckLoginValidity(user, &models.LoginUserData{Password: input.Password}); err != nil {
+			c.JSON(401, gin.H{"error": "Invalid password"})
+			return
+		}
+
+		valid, err := verifySecondFactor(db, userID, input.Code)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !valid {
+			c.JSON(401, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		if err := database.DisableTOTPByUserID(db, userID); err != nil {
+			c.JSON(500, gin.H{"error": "Could not disable two-factor authentication"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Two-factor authentication disabled"})
+	}
+}
+
+// replaces every recovery code, used or not
+func RegenerateRecoveryCodesHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		strid := c.Param("user_id")
+		userID, err := strconv.ParseInt(strid, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		var input models.TOTPCodeInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		valid, err := verifySecondFactor(db, userID, input.Code)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if !valid {
+			c.JSON(401, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		codes, hashes, err := newRecoveryCodes()
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err := database.ReplaceRecoveryCodes(db, userID, hashes); err != nil {
+			c.JSON(500, gin.H{"error": "Could not regenerate recovery codes"})
+			return
+		}
+
+		c.JSON(200, gin.H{"recovery_codes": codes})
+	}
+}
+
+// second step of a login for accounts with 2FA, exchanging the pending token and a code for a session
+func LoginTwoFactorHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input models.LoginTwoFactorInput
+
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": "Invalid input"})
+			return
+		}
+
+		userID, err := auth.CheckPendingTokenValidity(input.PendingToken)
+
+		if err != nil {
+			c.JSON(401, gin.H{"error": "Login expired, please sign in again"})
+			return
+		}
+
+		user, err := database.ReadUserByID(db, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if user == nil {
+			c.JSON(401, gin.H{"error": "Login expired, please sign in again"})
+			return
+		}
+
+		// wrong codes count towards the same lockout as wrong passwords
+		retryAfter, err := auth.LoginRetryAfter(db, user.Username, c.ClientIP())
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if retryAfter > 0 {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(429, gin.H{"error": "Too many failed login attempts", "retry_after": seconds})
+			return
+		}
+
+		valid, err := verifySecondFactor(db, userID, input.Code)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		attempt := models.LoginAttempt{
+			Username:  user.Username,
+			UserID:    &user.ID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+
+		if !valid {
+			if !recordLoginAttempt(c, db, &attempt) {
+				return
+			}
+
+			c.JSON(401, gin.H{"error": "Invalid code"})
+			return
+		}
+
+		completeLogin(c, db, user, &attempt, true)
+	}
+}
@@ -1,15 +1,81 @@
 package handlers
 
 import (
+	"backend/auth"
+	"backend/badges"
 	"backend/database"
 	"backend/models"
 	"database/sql"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"unicode/utf8"
 
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// answers the request itself when the new password breaks the policy
+func checkPasswordPolicy(c *gin.Context, password string, username string) bool {
+	err := auth.CheckPasswordPolicy(password, username)
+
+	if err == nil {
+		return true
+	}
+
+	var policyErr *auth.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		c.JSON(400, gin.H{"error": policyErr.Reason})
+		return false
+	}
+
+	log.Println(err)
+	c.JSON(500, gin.H{"error": "Internal server error"})
+
+	return false
+}
+
+// returns what is wrong with the profile fields being set, or "" when they are fine
+func validateProfileInput(input *models.UpdateUserInput) string {
+	fields := []struct {
+		name  string
+		value *string
+		limit int
+		isURL bool
+	}{
+		{"Display name", input.DisplayName, 50, false},
+		{"Bio", input.Bio, 500, false},
+		{"Location", input.Location, 100, false},
+		{"Avatar URL", input.AvatarURL, 500, true},
+		{"Website", input.Website, 500, true},
+	}
+
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+
+		*field.value = strings.TrimSpace(*field.value)
+
+		if utf8.RuneCountInString(*field.value) > field.limit {
+			return field.name + " must be at most " + strconv.Itoa(field.limit) + " characters"
+		}
+
+		// links are rendered for other users, so only plain web URLs are allowed
+		if field.isURL && *field.value != "" {
+			parsed, err := url.Parse(*field.value)
+
+			if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+				return field.name + " must be an http or https URL"
+			}
+		}
+	}
+
+	return ""
+}
+
 func CreateUserHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var input models.CreateUserInput
@@ -24,16 +90,62 @@ func CreateUserHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		if input.Email != "" && !isValidEmail(input.Email) {
+			c.JSON(400, gin.H{"error": "Invalid email"})
+			return
+		}
+
+		if !checkPasswordPolicy(c, input.Password, input.Username) {
+			return
+		}
+
+		ipBanned, err := database.IsIPBanned(db, c.ClientIP())
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if ipBanned {
+			c.JSON(403, gin.H{"error": "Registration not allowed"})
+			return
+		}
+
+		usernameBanned, err := database.IsUsernameBanned(db, input.Username)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if usernameBanned {
+			c.JSON(400, gin.H{"error": "Username not allowed"})
+			return
+		}
+
 		user := models.User{
 			Password: input.Password,
 			Username: input.Username,
 		}
 
+		if input.Email != "" {
+			user.Email = &input.Email
+		}
+
 		if err := database.CreateUser(db, &user); err != nil {
 			c.JSON(500, gin.H{"error": "Could not create user"})
 			return
 		}
 
+		if user.Email != nil {
+			if err := sendVerificationEmail(db, user.ID, *user.Email); err != nil {
+				log.Println(err)
+			}
+		}
+
+		c.Set("audit_target_type", "user")
+		c.Set("audit_target_id", user.ID)
+
 		c.JSON(201, gin.H{
 			"id":       user.ID,
 			"username": user.Username,
@@ -64,10 +176,18 @@ func ReadUserByIDHandler(db *sql.DB) gin.HandlerFunc {
 		}
 
 		c.JSON(200, gin.H{
-			"id":          user.ID,
-			"username":    user.Username,
-			"created_at":  user.CreatedAt,
-			"last_active": user.LastActive,
+			"id":             user.ID,
+			"username":       user.Username,
+			"created_at":     user.CreatedAt,
+			"last_active":    user.LastActive,
+			"email":          user.Email,
+			"email_verified": user.EmailVerified,
+			"display_name":   user.DisplayName,
+			"bio":            user.Bio,
+			"avatar_url":     user.AvatarURL,
+			"location":       user.Location,
+			"website":        user.Website,
+			"hide_activity":  user.HideActivity,
 		})
 	}
 }
@@ -96,6 +216,43 @@ func UpdateUserByIDHandler(db *sql.DB) gin.HandlerFunc {
 			c.JSON(400, gin.H{"error": "Password cannot be empty"})
 			return
 		}
+		if input.Email != nil && *input.Email != "" && !isValidEmail(*input.Email) {
+			c.JSON(400, gin.H{"error": "Invalid email"})
+			return
+		}
+		if problem := validateProfileInput(&input); problem != "" {
+			c.JSON(400, gin.H{"error": problem})
+			return
+		}
+
+		if input.Username != nil {
+			usernameBanned, err := database.IsUsernameBanned(db, *input.Username)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if usernameBanned {
+				c.JSON(400, gin.H{"error": "Username not allowed"})
+				return
+			}
+		}
+
+		if input.Password != nil {
+			var username string
+
+			if input.Username != nil {
+				username = *input.Username
+			} else if username, err = database.ReadUsernameByID(db, id); err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			if !checkPasswordPolicy(c, *input.Password, username) {
+				return
+			}
+		}
 
 		empty_update, user_not_found, err := database.UpdateUserByID(db, id, &input)
 		//consider emptying the password field
@@ -115,11 +272,17 @@ func UpdateUserByIDHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
+		if input.Email != nil && *input.Email != "" {
+			if err := sendVerificationEmail(db, id, *input.Email); err != nil {
+				log.Println(err)
+			}
+		}
+
 		c.JSON(200, gin.H{"status": "Updated successfully"})
 	}
 }
 
-func DeleteUserByIDHandler(db *sql.DB) gin.HandlerFunc {
+func ReadUsernameByIDHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		strid := c.Param("user_id")
 		id, err := strconv.ParseInt(strid, 10, 64)
@@ -129,45 +292,71 @@ func DeleteUserByIDHandler(db *sql.DB) gin.HandlerFunc {
 			return
 		}
 
-		user_not_found, err := database.DeleteUserByID(db, id)
-
+		username, err := database.ReadUsernameByID(db, id)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "Could not delete user"})
+			c.JSON(500, gin.H{"error": "Internal server error"})
 			return
 		}
 
-		if user_not_found {
+		if username == "" {
 			c.JSON(404, gin.H{"error": "User not found"})
 			return
 		}
 
-		c.JSON(200, gin.H{"status": "User deleted"})
+		c.JSON(200, gin.H{
+			"username": username,
+		})
 	}
 }
 
-func ReadUsernameByIDHandler(db *sql.DB) gin.HandlerFunc {
+// the public profile with activity stats and the latest posts and comments
+func ReadUserProfileHandler(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		strid := c.Param("user_id")
 		id, err := strconv.ParseInt(strid, 10, 64)
 
-		if err != nil {
+		if err != nil || id <= 0 {
 			c.JSON(400, gin.H{"error": "Invalid ID"})
 			return
 		}
 
-		username, err := database.ReadUsernameByID(db, id)
+		profile, err := database.ReadUserProfileByID(db, id)
+
 		if err != nil {
 			c.JSON(500, gin.H{"error": "Internal server error"})
 			return
 		}
 
-		if username == "" {
+		if profile == nil {
 			c.JSON(404, gin.H{"error": "User not found"})
 			return
 		}
 
-		c.JSON(200, gin.H{
-			"username": username,
-		})
+		profile.Badges, err = database.ReadBadgeByUserID(db, id)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		badges.Describe(profile.Badges)
+
+		var viewerID int64
+		if userIDVal, exists := c.Get("user_id"); exists {
+			viewerID, _ = userIDVal.(int64)
+		}
+
+		profile.RecentActivity = []models.ProfileActivity{}
+
+		if !profile.HideActivity || viewerID == id {
+			profile.RecentActivity, err = database.ReadRecentActivityByUserID(db, id, 10)
+
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				return
+			}
+		}
+
+		c.JSON(200, profile)
 	}
 }
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func isValidWatchLevel(level string) bool {
+	return level == models.WatchLevelAll || level == models.WatchLevelReplies || level == models.WatchLevelMuted
+}
+
+func CreateTopicWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicIDStr := c.Param("topic_id")
+		topicID, err := strconv.ParseInt(topicIDStr, 10, 64)
+		if err != nil || topicID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var input models.CreateWatchInput
+		// an empty body watches at the default level
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": "Invalid payload"})
+			return
+		}
+
+		if input.Level == "" {
+			input.Level = models.WatchLevelAll
+		}
+
+		if !isValidWatchLevel(input.Level) {
+			c.JSON(400, gin.H{"error": "Invalid watch level"})
+			return
+		}
+
+		topic, err := database.ReadTopicByID(db, topicID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if topic == nil {
+			c.JSON(404, gin.H{"error": "Topic not found"})
+			return
+		}
+
+		watch := models.TopicWatch{
+			TopicID: topicID,
+			UserID:  userID,
+			Level:   input.Level,
+		}
+
+		if err := database.UpsertTopicWatch(db, &watch); err != nil {
+			c.JSON(500, gin.H{"error": "Could not watch topic"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"topic_id":   watch.TopicID,
+			"level":      watch.Level,
+			"created_at": watch.CreatedAt,
+		})
+	}
+}
+
+func ReadTopicWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicIDStr := c.Param("topic_id")
+		topicID, err := strconv.ParseInt(topicIDStr, 10, 64)
+		if err != nil || topicID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		watch, err := database.ReadTopicWatchByTopicIDAndUserID(db, topicID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not get watch"})
+			return
+		}
+
+		if watch == nil {
+			c.JSON(200, gin.H{"level": nil})
+			return
+		}
+
+		c.JSON(200, gin.H{"level": watch.Level})
+	}
+}
+
+func DeleteTopicWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicIDStr := c.Param("topic_id")
+		topicID, err := strconv.ParseInt(topicIDStr, 10, 64)
+		if err != nil || topicID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		watch_not_found, err := database.DeleteTopicWatchByTopicIDAndUserID(db, topicID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unwatch topic"})
+			return
+		}
+
+		if watch_not_found {
+			c.JSON(404, gin.H{"error": "Watch not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Topic unwatched"})
+	}
+}
+
+func CreatePostWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var input models.CreateWatchInput
+		// an empty body watches at the default level
+		if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": "Invalid payload"})
+			return
+		}
+
+		if input.Level == "" {
+			input.Level = models.WatchLevelAll
+		}
+
+		if !isValidWatchLevel(input.Level) {
+			c.JSON(400, gin.H{"error": "Invalid watch level"})
+			return
+		}
+
+		post, err := database.ReadPostByID(db, postID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if post == nil {
+			c.JSON(404, gin.H{"error": "Post not found"})
+			return
+		}
+
+		watch := models.PostWatch{
+			PostID: postID,
+			UserID: userID,
+			Level:  input.Level,
+		}
+
+		if err := database.UpsertPostWatch(db, &watch); err != nil {
+			c.JSON(500, gin.H{"error": "Could not watch post"})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"post_id":    watch.PostID,
+			"level":      watch.Level,
+			"created_at": watch.CreatedAt,
+		})
+	}
+}
+
+func ReadPostWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		watch, err := database.ReadPostWatchByPostIDAndUserID(db, postID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not get watch"})
+			return
+		}
+
+		if watch == nil {
+			c.JSON(200, gin.H{"level": nil})
+			return
+		}
+
+		c.JSON(200, gin.H{"level": watch.Level})
+	}
+}
+
+func DeletePostWatchHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		postIDStr := c.Param("post_id")
+		postID, err := strconv.ParseInt(postIDStr, 10, 64)
+		if err != nil || postID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		watch_not_found, err := database.DeletePostWatchByPostIDAndUserID(db, postID, userID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Could not unwatch post"})
+			return
+		}
+
+		if watch_not_found {
+			c.JSON(404, gin.H{"error": "Watch not found"})
+			return
+		}
+
+		c.JSON(200, gin.H{"status": "Post unwatched"})
+	}
+}
+
+func ReadWatchedPostHandler(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			return
+		}
+
+		userID, match := userIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		pageStr := c.DefaultQuery("page", "1")
+		limitStr := c.DefaultQuery("limit", "10")
+
+		page, err := strconv.Atoi(pageStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid page"})
+			return
+		}
+
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "Invalid limit"})
+			return
+		}
+
+		if page <= 0 {
+			page = 1
+		}
+
+		if limit < 10 || limit >= 100 {
+			limit = 10
+		}
+
+		offset := (page - 1) * limit
+
+		postsData, err := database.ReadWatchedPost(db, userID, limit, offset)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if len(postsData) == 0 {
+			c.JSON(200, gin.H{
+				"count": 0,
+				"page":  page,
+				"limit": limit,
+				"posts": []models.WatchedPost{},
+			})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"count": len(postsData),
+			"page":  page,
+			"limit": limit,
+			"posts": postsData,
+		})
+	}
+}
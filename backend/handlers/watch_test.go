@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"backend/database"
+	"backend/models"
+	"strconv"
+	"testing"
+)
+
+func TestCreateWatchLevels(t *testing.T) {
+	db := testDB(t)
+
+	user := testUser(t, db, "")
+
+	topic := models.Topic{Title: "test topic", Description: "test topic", CreatedBy: user.ID}
+	if err := database.CreateTopic(db, &topic); err != nil {
+		t.Fatal(err)
+	}
+
+	post := models.Post{Title: "test post", Description: "test post", TopicID: topic.ID, CreatedBy: user.ID}
+	if err := database.CreatePost(db, &post); err != nil {
+		t.Fatal(err)
+	}
+
+	router := routerAs(user)
+	router.POST("/topics/:topic_id/watch", CreateTopicWatchHandler(db))
+	router.POST("/posts/:post_id/watch", CreatePostWatchHandler(db))
+
+	paths := []string{
+		"/topics/" + strconv.FormatInt(topic.ID, 10) + "/watch",
+		"/posts/" + strconv.FormatInt(post.ID, 10) + "/watch",
+	}
+
+	cases := []struct {
+		body  string
+		code  int
+		level string
+	}{
+		{"", 200, models.WatchLevelAll},
+		{"{}", 200, models.WatchLevelAll},
+		{`{"level": "muted"}`, 200, models.WatchLevelMuted},
+		{`{"level": "loud"}`, 400, ""},
+		{"{", 400, ""},
+	}
+
+	for _, path := range paths {
+		for _, tc := range cases {
+			recorder := serveJSON(router, "POST", path, tc.body)
+
+			if recorder.Code != tc.code {
+				t.Fatalf("POST %s with %q got %d, want %d", path, tc.body, recorder.Code, tc.code)
+			}
+
+			if tc.code == 200 && decodeBody(t, recorder)["level"] != tc.level {
+				t.Fatalf("POST %s with %q did not watch at %s", path, tc.body, tc.level)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mailer
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+)
+
+var ErrInvalidHeader = errors.New("mail header contains a newline")
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+var defaultMailer Mailer
+var defaultMailerOnce sync.Once
+
+// Sends through the mailer configured from the environment, built on first use
+func Send(to string, subject string, body string) error {
+	defaultMailerOnce.Do(func() {
+		defaultMailer = FromEnv()
+	})
+
+	return defaultMailer.Send(to, subject, body)
+}
+
+// MAILER selects the implementation:
+//
+//	smtp  sends through SMTP_HOST and SMTP_PORT (default 587), logging in with SMTP_USERNAME and SMTP_PASSWORD
+//	file  writes each message to a file in MAIL_DIR (default "mail")
+//	log   prints each message to the log with link tokens redacted, the default
+//
+// MAIL_FROM sets the sender address.
+func FromEnv() Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@chatit.local"
+	}
+
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+
+		return &FileMailer{Dir: dir, From: from}
+	case "", "log":
+		return &LogMailer{From: from}
+	}
+
+	log.Println("MAILER: unknown mailer, falling back to log")
+
+	return &LogMailer{From: from}
+}
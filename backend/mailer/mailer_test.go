@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogMailerRedactsTokens(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	body := "reset here: https://example.com/reset_password?token=s3cr3t-t0ken&x=1\nor https://example.com/verify_email?token=an0ther"
+
+	if err := (&LogMailer{From: "no-reply@example.com"}).Send("user@example.com", "Reset", body); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := out.String()
+
+	for _, secret := range []string{"s3cr3t-t0ken", "an0ther"} {
+		if strings.Contains(logged, secret) {
+			t.Fatalf("token %q logged: %s", secret, logged)
+		}
+	}
+
+	if !strings.Contains(logged, "reset_password?token=[redacted]&x=1") {
+		t.Fatalf("link not kept with the token redacted: %s", logged)
+	}
+}
+
+func TestFileMailerKeepsTokens(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := (&FileMailer{Dir: dir, From: "no-reply@example.com"}).Send("user@example.com", "Reset", "https://example.com/reset_password?token=s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("%d files written, want 1", len(files))
+	}
+
+	content, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(content), "token=s3cr3t") {
+		t.Fatalf("file mailer lost the token: %s", content)
+	}
+}
+
+func TestHeaderInjection(t *testing.T) {
+	mailers := []Mailer{&LogMailer{}, &FileMailer{Dir: t.TempDir()}}
+
+	for _, m := range mailers {
+		if err := m.Send("user@example.com\r\nBcc: other@example.com", "Reset", "body"); err != ErrInvalidHeader {
+			t.Errorf("%T accepted a newline in the recipient: %v", m, err)
+		}
+	}
+}
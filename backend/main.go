@@ -9,14 +9,24 @@ import (
 
 	"fmt"
 
+	"backend/auth"
+	"backend/badges"
 	"backend/database"
+	"backend/deletion"
+	"backend/export"
 	"backend/handlers"
 	"backend/middleware"
+	"backend/models"
 
 	_ "github.com/lib/pq"
 )
 
 func main() {
+	// refuse to start without usable signing keys
+	if err := auth.LoadKeys(); err != nil {
+		log.Fatal(err)
+	}
+
 	db, err := database.ConnectDB()
 	if err != nil {
 		log.Fatal(err)
@@ -32,6 +42,10 @@ func main() {
 
 	fmt.Println("DB created")
 
+	export.Start(db)
+	deletion.Start(db)
+	badges.Start(db)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080" // fallback for local testing
@@ -39,26 +53,58 @@ func main() {
 
 	router := gin.Default()
 
+	// client IPs key the rate limits and login lockout, so forwarded headers are only believed from known proxies
+	if err := router.SetTrustedProxies(middleware.TrustedProxies()); err != nil {
+		log.Fatal(err)
+	}
+
+	rateLimitStore := middleware.NewMemoryRateLimitStore()
+	publicRateLimit := middleware.NewRateLimitPolicy("public", "RATE_LIMIT_PUBLIC", "120/1m")
+	loggedInRateLimit := middleware.NewRateLimitPolicy("logged_in", "RATE_LIMIT_LOGGED_IN", "300/1m")
+	authRateLimit := middleware.NewRateLimitPolicy("auth", "RATE_LIMIT_AUTH", "10/1m")
+
 	routes := router.Group("/")
-	routes.Use(middleware.EnableCORS())
+	routes.Use(middleware.EnableCORS(), middleware.RequestID(), middleware.CSRF(), middleware.Audit(db))
 
 	// Catching OPTIONS
 	routes.OPTIONS("/*path")
 
+	routes.GET("/.well-known/jwks.json", handlers.ReadJWKSHandler())
+
 	// PUBLIC ROUTES (No Authentication Required)
 	public := routes.Group("/public")
-	public.Use(middleware.JWTAuthorisationPublic())
+	public.Use(middleware.JWTAuthorisationPublic(db), middleware.RateLimit(rateLimitStore, publicRateLimit))
 	{
 		//Return User ID
 		public.GET("/auth/loginStatus", handlers.ReadLoggedInUserID(db))
 
 		// Authentication Routes
-		public.POST("/auth/register", handlers.CreateUserHandler(db))
-		public.POST("/auth/login", handlers.LoginHandler(db))
+		public.POST("/auth/register", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.CreateUserHandler(db))
+		public.POST("/auth/login", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.LoginHandler(db))
+		public.POST("/auth/login/2fa", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.LoginTwoFactorHandler(db))
+		public.POST("/auth/verify_email", handlers.VerifyEmailHandler(db))
+		public.POST("/auth/forgot_password", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.ForgotPasswordHandler(db))
+		public.POST("/auth/reset_password", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.ResetPasswordHandler(db))
 		public.POST("/auth/logout", handlers.LogoutHandler(db))
+		public.GET("/auth/csrf", handlers.ReadCSRFTokenHandler(db))
+
+		public.GET("/auth/oidc/providers", handlers.ReadOIDCProvidersHandler(db))
+		public.GET("/auth/oidc/:provider/login", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.StartOIDCLoginHandler(db))
+		public.GET("/auth/oidc/:provider/callback", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.OIDCCallbackHandler(db))
+		public.POST("/auth/oidc/signup", middleware.RateLimit(rateLimitStore, authRateLimit), handlers.OIDCSignupHandler(db))
 
 		// User Routes - Read Only
 		public.GET("/users/:user_id", handlers.ReadUsernameByIDHandler(db))
+		public.GET("/users/:user_id/profile", handlers.ReadUserProfileHandler(db))
+		public.GET("/users/:user_id/reputation", handlers.ReadReputationByUserIDHandler(db))
+		public.GET("/users/:user_id/posts", handlers.ReadPostByUserIDHandler(db))
+		public.GET("/users/:user_id/comments", handlers.ReadCommentByUserIDHandler(db))
+		public.GET("/users/:user_id/followers", handlers.ReadFollowerHandler(db))
+		public.GET("/users/:user_id/following", handlers.ReadFollowingHandler(db))
+
+		// Badge Routes
+		public.GET("/badges", handlers.ReadBadgeHandler(db))
+		public.GET("/badges/recent", handlers.ReadRecentBadgeHandler(db))
 
 		// Topic Routes - Read Only
 		public.GET("/topics", handlers.ReadTopicHandler(db))
@@ -68,6 +114,7 @@ func main() {
 		// Post Routes - Read Only (Public Feed)
 		public.GET("/posts", handlers.ReadPostHandler(db))
 		public.GET("/posts/:post_id", handlers.ReadPostByIDHandler(db))
+		public.GET("/posts/:post_id/poll", handlers.ReadPollByPostIDHandler(db))
 		public.PATCH("/posts/:post_id", handlers.UpdatePostViewsByIDHandler(db))
 		public.GET("/topics/:topic_id/posts", handlers.ReadPostByTopicIDHandler(db))
 		public.GET("/topics/:topic_id/posts/search", handlers.ReadPostBySearchQueryHandler(db))
@@ -79,12 +126,35 @@ func main() {
 
 	// PROTECTED ROUTES (Authentication Required)
 	protected := routes.Group("/logged_in")
-	protected.Use(middleware.JWTAuthorisation())
+	protected.Use(middleware.JWTAuthorisation(db), middleware.RateLimit(rateLimitStore, loggedInRateLimit))
 	{
 		// USER CRUD
 		protected.GET("/users/:user_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadUserByIDHandler(db))
 		protected.PATCH("/users/:user_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.UpdateUserByIDHandler(db))
 		protected.DELETE("/users/:user_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.DeleteUserByIDHandler(db))
+		protected.GET("/users/:user_id/deletion", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadAccountDeletionHandler(db))
+		protected.DELETE("/users/:user_id/deletion", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.CancelAccountDeletionHandler(db))
+		protected.GET("/users/:user_id/logins", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadLoginAttemptHandler(db))
+		protected.GET("/users/:user_id/reactions", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadReactionByUserIDHandler(db))
+		protected.POST("/users/:user_id/email/verification", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.RequestEmailVerificationHandler(db))
+
+		// TWO-FACTOR AUTHENTICATION
+		protected.POST("/users/:user_id/2fa/enroll", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.EnrollTOTPHandler(db))
+		protected.POST("/users/:user_id/2fa/activate", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ActivateTOTPHandler(db))
+		protected.POST("/users/:user_id/2fa/disable", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.DisableTOTPHandler(db))
+		protected.POST("/users/:user_id/2fa/recovery_codes", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.RegenerateRecoveryCodesHandler(db))
+
+		protected.GET("/users/:user_id/identities", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadIdentityHandler(db))
+		protected.POST("/users/:user_id/identities/:provider/link", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.StartOIDCLinkHandler(db))
+		protected.DELETE("/users/:user_id/identities/:identity_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.DeleteIdentityByIDHandler(db))
+
+		protected.POST("/users/:user_id/tokens", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.CreateAccessTokenHandler(db))
+		protected.GET("/users/:user_id/tokens", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadAccessTokenHandler(db))
+		protected.DELETE("/users/:user_id/tokens/:token_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.DeleteAccessTokenByIDHandler(db))
+
+		protected.POST("/users/:user_id/exports", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.CreateDataExportHandler(db))
+		protected.GET("/users/:user_id/exports/:export_id", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.ReadDataExportByIDHandler(db))
+		protected.GET("/users/:user_id/exports/:export_id/download", middleware.CheckOwnershipByID(db, database.GetUserOwnerByID), handlers.DownloadDataExportHandler(db))
 
 		//TOPIC CRUD
 		protected.POST("/topics", handlers.CreateTopicHandler(db))
@@ -95,6 +165,8 @@ func main() {
 		protected.POST("topics/:topic_id/posts", handlers.CreatePostHandler(db))
 		protected.PATCH("/posts/:post_id", middleware.CheckOwnershipByID(db, database.GetPostOwnerByID), handlers.UpdatePostByIDHandler(db))
 		protected.DELETE("/posts/:post_id", middleware.CheckOwnershipByID(db, database.GetPostOwnerByID), handlers.DeletePostByIDHandler(db))
+		protected.POST("/posts/:post_id/accepted_answer", middleware.CheckOwnershipByID(db, database.GetPostOwnerByID), handlers.UpdateAcceptedAnswerHandler(db))
+		protected.DELETE("/posts/:post_id/accepted_answer", middleware.CheckOwnershipByID(db, database.GetPostOwnerByID), handlers.DeleteAcceptedAnswerHandler(db))
 
 		//COMMENT CRUD
 		protected.POST("/comments", handlers.CreateCommentHandler(db))
@@ -106,10 +178,90 @@ func main() {
 		protected.DELETE("/posts/:post_id/reactions", handlers.DeletePostReactionHandler(db))
 		protected.GET("/posts/:post_id/reactions", handlers.ReadPostReactionHandler(db))
 
+		//POLLS
+		protected.POST("/posts/:post_id/poll/votes", handlers.CreatePollVoteHandler(db))
+		protected.PATCH("/posts/:post_id/poll/votes", handlers.UpdatePollVoteHandler(db))
+		protected.DELETE("/posts/:post_id/poll/votes", handlers.DeletePollVoteHandler(db))
+
 		//COMMENT REACTIONS
 		protected.POST("/comments/:comment_id/reactions", handlers.CreateCommentReactionHandler(db))
 		protected.DELETE("/comments/:comment_id/reactions", handlers.DeleteCommentReactionHandler(db))
 		protected.GET("/comments/:comment_id/reactions", handlers.ReadCommentReactionHandler(db))
+
+		//WATCHES
+		protected.POST("/topics/:topic_id/watch", handlers.CreateTopicWatchHandler(db))
+		protected.DELETE("/topics/:topic_id/watch", handlers.DeleteTopicWatchHandler(db))
+		protected.GET("/topics/:topic_id/watch", handlers.ReadTopicWatchHandler(db))
+		protected.POST("/posts/:post_id/watch", handlers.CreatePostWatchHandler(db))
+		protected.DELETE("/posts/:post_id/watch", handlers.DeletePostWatchHandler(db))
+		protected.GET("/posts/:post_id/watch", handlers.ReadPostWatchHandler(db))
+		protected.GET("/watched", handlers.ReadWatchedPostHandler(db))
+
+		//FOLLOWS
+		protected.POST("/users/:user_id/follow", handlers.CreateFollowHandler(db))
+		protected.DELETE("/users/:user_id/follow", handlers.DeleteFollowHandler(db))
+		protected.GET("/feed", handlers.ReadHomeFeedHandler(db))
+
+		//BLOCKS AND MUTES
+		protected.POST("/users/:user_id/block", handlers.CreateBlockHandler(db))
+		protected.DELETE("/users/:user_id/block", handlers.DeleteBlockHandler(db))
+		protected.GET("/blocks", handlers.ReadBlockedHandler(db))
+		protected.POST("/users/:user_id/mute", handlers.CreateMuteHandler(db))
+		protected.DELETE("/users/:user_id/mute", handlers.DeleteMuteHandler(db))
+		protected.GET("/mutes", handlers.ReadMutedHandler(db))
+
+		//DIRECT MESSAGES
+		protected.POST("/conversations", handlers.CreateConversationHandler(db))
+		protected.GET("/conversations", handlers.ReadConversationHandler(db))
+		protected.GET("/conversations/unread", handlers.ReadUnreadMessageCountHandler(db))
+		protected.GET("/conversations/:conversation_id", middleware.CheckConversationParticipant(db), handlers.ReadConversationByIDHandler(db))
+		protected.GET("/conversations/:conversation_id/messages", middleware.CheckConversationParticipant(db), handlers.ReadMessageHandler(db))
+		protected.POST("/conversations/:conversation_id/messages", middleware.CheckConversationParticipant(db), handlers.CreateMessageHandler(db))
+		protected.POST("/conversations/:conversation_id/read", middleware.CheckConversationParticipant(db), handlers.UpdateReadReceiptHandler(db))
+		protected.PATCH("/messages/:message_id", middleware.CheckOwnershipByID(db, database.GetMessageOwnerByID), handlers.UpdateMessageByIDHandler(db))
+		protected.DELETE("/messages/:message_id", middleware.CheckOwnershipByID(db, database.GetMessageOwnerByID), handlers.DeleteMessageByIDHandler(db))
+
+		//REPORTS
+		protected.POST("/reports", handlers.CreateReportHandler(db))
+	}
+
+	// MODERATION ROUTES (Moderator Role Required)
+	moderation := protected.Group("/moderation")
+	moderation.Use(middleware.RequireRole(db, models.RoleModerator, models.RoleAdmin))
+	{
+		moderation.GET("/reports", handlers.ReadReportQueueHandler(db))
+		moderation.GET("/reports/:target_type/:target_id", handlers.ReadReportByTargetHandler(db))
+		moderation.POST("/reports/:target_type/:target_id/resolve", handlers.ResolveReportHandler(db))
+
+		moderation.POST("/users/:user_id/suspensions", handlers.CreateSuspensionHandler(db))
+		moderation.GET("/users/:user_id/suspensions", handlers.ReadSuspensionByUserIDHandler(db))
+		moderation.DELETE("/suspensions/:suspension_id", handlers.LiftSuspensionByIDHandler(db))
+		moderation.POST("/users/:user_id/reputation/penalties", handlers.CreateReputationPenaltyHandler(db))
+
+		moderation.GET("/held", handlers.ReadHeldContentHandler(db))
+		moderation.POST("/held/:held_id/approve", handlers.ApproveHeldContentHandler(db))
+		moderation.POST("/held/:held_id/reject", handlers.RejectHeldContentHandler(db))
+
+		moderation.POST("/blocked_terms", handlers.CreateBlockedTermHandler(db))
+		moderation.GET("/blocked_terms", handlers.ReadBlockedTermHandler(db))
+		moderation.DELETE("/blocked_terms/:blocked_term_id", handlers.DeleteBlockedTermByIDHandler(db))
+	}
+
+	// ADMIN ROUTES (Admin Role Required)
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireRole(db, models.RoleAdmin))
+	{
+		admin.PATCH("/users/:user_id/role", handlers.UpdateUserRoleByIDHandler(db))
+
+		admin.POST("/banned_ips", handlers.CreateBannedIPHandler(db))
+		admin.GET("/banned_ips", handlers.ReadBannedIPHandler(db))
+		admin.DELETE("/banned_ips/:banned_ip_id", handlers.DeleteBannedIPByIDHandler(db))
+		admin.POST("/banned_usernames", handlers.CreateBannedUsernameHandler(db))
+		admin.GET("/banned_usernames", handlers.ReadBannedUsernameHandler(db))
+		admin.DELETE("/banned_usernames/:banned_username_id", handlers.DeleteBannedUsernameByIDHandler(db))
+
+		admin.GET("/audit", handlers.ReadAuditLogHandler(db))
+		admin.GET("/audit/export", handlers.ExportAuditLogHandler(db))
 	}
 
 	router.Run(":" + port)
@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tags every request with an ID, reusing the client's X-Request-ID when present
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader("X-Request-ID")
+
+		if requestID == "" || len(requestID) > 64 {
+			bytes := make([]byte, 16)
+			rand.Read(bytes)
+			requestID = hex.EncodeToString(bytes)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header("X-Request-ID", requestID)
+
+		c.Next()
+	}
+}
+
+type snapshotFetcher func(*sql.DB, int64) (interface{}, error)
+
+// route parameters identifying the target of a request, most specific first
+var auditTargetParams = []struct {
+	param      string
+	targetType string
+}{
+	{"message_id", "message"},
+	{"comment_id", models.TargetTypeComment},
+	{"post_id", models.TargetTypePost},
+	{"topic_id", models.TargetTypeTopic},
+	{"conversation_id", "conversation"},
+	{"suspension_id", "suspension"},
+	{"banned_ip_id", "banned_ip"},
+	{"banned_username_id", "banned_username"},
+	{"held_id", "held_content"},
+	{"blocked_term_id", "blocked_term"},
+	{"identity_id", "identity"},
+	{"token_id", "access_token"},
+	{"export_id", "data_export"},
+	{"user_id", models.TargetTypeUser},
+}
+
+var auditSnapshotFetchers = map[string]snapshotFetcher{
+	// private messages are audited without their body
+	"message": func(db *sql.DB, id int64) (interface{}, error) {
+		message, err := database.ReadMessageByID(db, id)
+
+		if err != nil || message == nil {
+			return nil, err
+		}
+
+		message.Body = ""
+
+		return message, nil
+	},
+	models.TargetTypeComment: func(db *sql.DB, id int64) (interface{}, error) {
+		return database.ReadCommentByID(db, id)
+	},
+	models.TargetTypePost: func(db *sql.DB, id int64) (interface{}, error) {
+		return database.ReadPostByID(db, id)
+	},
+	models.TargetTypeTopic: func(db *sql.DB, id int64) (interface{}, error) {
+		return database.ReadTopicByID(db, id)
+	},
+	models.TargetTypeUser: func(db *sql.DB, id int64) (interface{}, error) {
+		return database.ReadUserByID(db, id)
+	},
+}
+
+// anonymous bookkeeping requests that are not worth an audit entry
+var auditSkippedRoutes = map[string]bool{
+	"PATCH /public/posts/:post_id": true,
+}
+
+func auditSnapshot(db *sql.DB, targetType string, targetID int64) json.RawMessage {
+	fetcher, exists := auditSnapshotFetchers[targetType]
+	if !exists {
+		return nil
+	}
+
+	data, err := fetcher(db, targetID)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	snapshot, err := json.Marshal(data)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	return snapshot
+}
+
+// Records every successful mutating request in the audit log with before/after snapshots of its target.
+// Handlers creating a resource can name it with c.Set("audit_target_type") and c.Set("audit_target_id").
+func Audit(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if (method != "POST" && method != "PATCH" && method != "PUT" && method != "DELETE") || auditSkippedRoutes[method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		var targetType string
+		var targetID int64
+
+		if c.Param("target_type") != "" {
+			targetType = c.Param("target_type")
+			targetID, _ = strconv.ParseInt(c.Param("target_id"), 10, 64)
+		} else {
+			for _, target := range auditTargetParams {
+				if idStr := c.Param(target.param); idStr != "" {
+					targetType = target.targetType
+					targetID, _ = strconv.ParseInt(idStr, 10, 64)
+					break
+				}
+			}
+		}
+
+		var before json.RawMessage
+		if targetID > 0 {
+			before = auditSnapshot(db, targetType, targetID)
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= 400 {
+			return
+		}
+
+		// a newly created resource takes precedence over the parent named in the route
+		if createdType := c.GetString("audit_target_type"); createdType != "" {
+			targetType = createdType
+			targetID = c.GetInt64("audit_target_id")
+			before = nil
+		}
+
+		entry := models.AuditEntry{
+			Action:    method + " " + c.FullPath(),
+			RequestID: c.GetString("request_id"),
+			IP:        c.ClientIP(),
+			Status:    status,
+			Before:    before,
+		}
+
+		if userIDVal, exists := c.Get("user_id"); exists {
+			if userID, match := userIDVal.(int64); match {
+				entry.ActorID = &userID
+			}
+		}
+
+		if targetID > 0 {
+			entry.TargetType = &targetType
+			entry.TargetID = &targetID
+			entry.After = auditSnapshot(db, targetType, targetID)
+		}
+
+		if err := database.CreateAuditEntry(db, &entry); err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"backend/auth"
+	"backend/database"
+	"backend/models"
 	"database/sql"
 	"log"
 	"strconv"
@@ -11,45 +13,131 @@ import (
 )
 
 // Mandatory verification of privte routes
-func JWTAuthorisation() gin.HandlerFunc {
+// banned users are rejected outright, suspended users are limited to read-only requests
+func JWTAuthorisation(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenStr, err := c.Cookie("token")
-		if err != nil {
-			c.JSON(401, gin.H{"error": "Missing token"})
-			c.Abort()
-			return
-		}
+		var currentUserID int64
 
-		token, err := auth.CheckTokenValidity(tokenStr)
+		// scripts and bots send a personal access token instead of the session cookie
+		if bearer := bearerToken(c); bearer != "" {
+			accessToken, err := readAccessToken(db, bearer)
 
-		if err != nil || !token.Valid {
-			c.JSON(401, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
+			if err != nil {
+				c.JSON(500, gin.H{"error": "Internal server error"})
+				c.Abort()
+				return
+			}
 
-		if claims, match := token.Claims.(jwt.MapClaims); match {
-			if userID, match := claims["user_id"].(float64); match {
-				c.Set("user_id", int64(userID))
+			if accessToken == nil {
+				c.JSON(401, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
+
+			scope := requiredScope(c.Request.Method, c.FullPath())
+
+			if scope == "" || !hasScope(accessToken.Scopes, scope) {
+				c.JSON(403, gin.H{"error": "Token not allowed for this request", "required_scope": scope})
+				c.Abort()
+				return
+			}
+
+			currentUserID = accessToken.UserID
+			c.Set("user_id", currentUserID)
+			c.Set("token_scopes", accessToken.Scopes)
+		} else {
+			tokenStr, err := c.Cookie("token")
+			if err != nil {
+				c.JSON(401, gin.H{"error": "Missing token"})
+				c.Abort()
+				return
+			}
+
+			token, err := auth.CheckTokenValidity(tokenStr)
+
+			if err != nil || !token.Valid || auth.IsPendingToken(token) {
+				c.JSON(401, gin.H{"error": "Invalid token"})
+				c.Abort()
+				return
+			}
 
+			if claims, match := token.Claims.(jwt.MapClaims); match {
+				if userID, match := claims["user_id"].(float64); match {
+					currentUserID = int64(userID)
+
+					revoked, err := sessionRevoked(db, currentUserID, claims)
+
+					if err != nil {
+						c.JSON(500, gin.H{"error": "Internal server error"})
+						c.Abort()
+						return
+					}
+
+					if revoked {
+						c.JSON(401, gin.H{"error": "Session expired, please sign in again"})
+						c.Abort()
+						return
+					}
+
+					c.Set("user_id", currentUserID)
+
+				} else {
+					c.JSON(401, gin.H{"error": "Invalid token"})
+					c.Abort()
+					return
+				}
 			} else {
 				c.JSON(401, gin.H{"error": "Invalid token"})
 				c.Abort()
 				return
 			}
-		} else {
-			c.JSON(401, gin.H{"error": "Invalid token"})
+		}
+
+		suspension, err := database.ReadActiveSuspensionByUserID(db, currentUserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
 			c.Abort()
 			return
 		}
 
+		if suspension != nil {
+			if suspension.ExpiresAt == nil {
+				c.JSON(403, gin.H{"error": "Account banned", "reason": suspension.Reason})
+				c.Abort()
+				return
+			}
+
+			if c.Request.Method != "GET" {
+				c.JSON(403, gin.H{
+					"error":           "Account suspended",
+					"reason":          suspension.Reason,
+					"suspended_until": suspension.ExpiresAt,
+				})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
 
 // Optional verification for public routes
-func JWTAuthorisationPublic() gin.HandlerFunc {
+func JWTAuthorisationPublic(db *sql.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if bearer := bearerToken(c); bearer != "" {
+			accessToken, err := readAccessToken(db, bearer)
+
+			if err == nil && accessToken != nil && hasScope(accessToken.Scopes, models.ScopeRead) {
+				c.Set("user_id", accessToken.UserID)
+				c.Set("token_scopes", accessToken.Scopes)
+			}
+
+			c.Next()
+			return
+		}
+
 		tokenStr, err := c.Cookie("token")
 		if err != nil {
 			log.Println("WHY")
@@ -59,14 +147,16 @@ func JWTAuthorisationPublic() gin.HandlerFunc {
 
 		token, err := auth.CheckTokenValidity(tokenStr)
 
-		if err != nil || !token.Valid {
+		if err != nil || !token.Valid || auth.IsPendingToken(token) {
 			c.Next()
 			return
 		}
 
 		if claims, match := token.Claims.(jwt.MapClaims); match {
 			if userID, match := claims["user_id"].(float64); match {
-				c.Set("user_id", int64(userID))
+				if revoked, err := sessionRevoked(db, int64(userID), claims); err == nil && !revoked {
+					c.Set("user_id", int64(userID))
+				}
 			}
 		}
 
@@ -74,11 +164,25 @@ func JWTAuthorisationPublic() gin.HandlerFunc {
 	}
 }
 
+// sessions issued before the user's last password reset no longer count
+func sessionRevoked(db *sql.DB, userID int64, claims jwt.MapClaims) (bool, error) {
+	changedAt, err := database.ReadPasswordChangedAtByUserID(db, userID)
+
+	if err != nil || changedAt == nil {
+		return false, err
+	}
+
+	issuedAt, match := claims["iat"].(float64)
+
+	return !match || int64(issuedAt) < changedAt.Unix(), nil
+}
+
 type resourceFetcher func(*sql.DB, int64) (int64, error)
 
 func CheckOwnershipByID(db *sql.DB, fetcher resourceFetcher) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
+		messageID := c.Param("message_id")
 		commentID := c.Param("comment_id")
 		postID := c.Param("post_id")
 		topicID := c.Param("topic_id")
@@ -86,7 +190,9 @@ func CheckOwnershipByID(db *sql.DB, fetcher resourceFetcher) gin.HandlerFunc {
 
 		resourceIDStr := ""
 
-		if commentID != "" {
+		if messageID != "" {
+			resourceIDStr = messageID
+		} else if commentID != "" {
 			resourceIDStr = commentID
 		} else if postID != "" {
 			resourceIDStr = postID
@@ -144,13 +250,99 @@ func CheckOwnershipByID(db *sql.DB, fetcher resourceFetcher) gin.HandlerFunc {
 	}
 }
 
+// Only participants may access a conversation
+func CheckConversationParticipant(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID, err := strconv.ParseInt(c.Param("conversation_id"), 10, 64)
+
+		if err != nil || conversationID <= 0 {
+			c.JSON(400, gin.H{"error": "Invalid ID"})
+			c.Abort()
+			return
+		}
+
+		currentUserIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			c.Abort()
+			return
+		}
+
+		currentUserID, match := currentUserIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		participant, err := database.IsConversationParticipant(db, conversationID, currentUserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			c.Abort()
+			return
+		}
+
+		if !participant {
+			c.JSON(404, gin.H{"error": "Conversation not found"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Restricts routes to users holding one of the given roles
+func RequireRole(db *sql.DB, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		currentUserIDVal, exists := c.Get("user_id")
+
+		if !exists {
+			c.JSON(401, gin.H{"error": "Not logged in"})
+			c.Abort()
+			return
+		}
+
+		currentUserID, match := currentUserIDVal.(int64)
+
+		if !match {
+			c.JSON(401, gin.H{"error": "Invalid user ID"})
+			c.Abort()
+			return
+		}
+
+		role, err := database.ReadUserRoleByID(db, currentUserID)
+
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Internal server error"})
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Set("role", role)
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(403, gin.H{"error": "Unauthorised"})
+		c.Abort()
+	}
+}
+
 func EnableCORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		c.Header("Access-Control-Allow-Origin", "https://cvwo-chatit.onrender.com")
 		c.Header("Access-Control-Allow-Methods", "POST, GET, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-CSRF-Token")
 		c.Header("Access-Control-Allow-Credentials", "true")
+		c.Header("Access-Control-Expose-Headers", "Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
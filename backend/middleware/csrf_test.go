@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"backend/auth"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	os.Exit(m.Run())
+}
+
+func TestCSRF(t *testing.T) {
+	t.Setenv("CSRF_TRUSTED_ORIGINS", "https://chatit.example/, https://other.example")
+
+	router := gin.New()
+	router.Use(CSRF())
+	router.Any("/", func(c *gin.Context) {
+		c.Status(204)
+	})
+
+	cases := []struct {
+		name    string
+		method  string
+		session bool
+		cookie  string
+		headers map[string]string
+		code    int
+	}{
+		{"safe method", "GET", true, "", nil, 204},
+		{"no session", "POST", false, "", nil, 204},
+		{"bearer token", "POST", true, "", map[string]string{"Authorization": "Bearer abc"}, 204},
+		{"matching token", "POST", true, "abc", map[string]string{auth.CSRFHeaderName: "abc"}, 204},
+		{"trusted origin", "DELETE", true, "abc", map[string]string{auth.CSRFHeaderName: "abc", "Origin": "https://other.example"}, 204},
+		{"trusted referer", "PATCH", true, "abc", map[string]string{auth.CSRFHeaderName: "abc", "Referer": "https://chatit.example/posts/1"}, 204},
+		{"missing header", "POST", true, "abc", nil, 403},
+		{"missing cookie", "POST", true, "", map[string]string{auth.CSRFHeaderName: "abc"}, 403},
+		{"empty token", "POST", true, "", map[string]string{auth.CSRFHeaderName: ""}, 403},
+		{"wrong token", "POST", true, "abc", map[string]string{auth.CSRFHeaderName: "abd"}, 403},
+		{"untrusted origin", "POST", true, "abc", map[string]string{auth.CSRFHeaderName: "abc", "Origin": "https://evil.example"}, 403},
+		{"untrusted referer", "POST", true, "abc", map[string]string{auth.CSRFHeaderName: "abc", "Referer": "https://evil.example/chatit.example"}, 403},
+	}
+
+	for _, tc := range cases {
+		request := httptest.NewRequest(tc.method, "/", nil)
+
+		if tc.session {
+			request.Header.Add("Cookie", "token=session")
+		}
+		if tc.cookie != "" {
+			request.Header.Add("Cookie", auth.CSRFCookieName+"="+tc.cookie)
+		}
+		for name, value := range tc.headers {
+			request.Header.Set(name, value)
+		}
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tc.code {
+			t.Errorf("%s: got %d, want %d", tc.name, recorder.Code, tc.code)
+		}
+	}
+}
+
+func TestTrustedOrigins(t *testing.T) {
+	t.Setenv("CSRF_TRUSTED_ORIGINS", "")
+
+	if origins := trustedOrigins(); len(origins) != 1 || origins[0] != "https://cvwo-chatit.onrender.com" {
+		t.Fatalf("default origins are %v", origins)
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"os"
+	"strings"
+)
+
+// proxies allowed to set X-Forwarded-For, as IPs or CIDRs; with none, ClientIP is the connection's address
+func TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"backend/utils"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy allows Limit requests per Window, refilled continuously as a token bucket.
+// Name keeps the buckets of different policies apart.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // until the next token, when not allowed
+	Reset      time.Duration // until the bucket is full again
+}
+
+// RateLimitStore holds the token buckets. The in-memory store only limits a single instance;
+// a shared store (e.g. Redis) can be plugged in for several instances behind a load balancer.
+type RateLimitStore interface {
+	Take(key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+// starts a janitor removing buckets idle for longer than an hour
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	store := &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}}
+
+	go func() {
+		for range time.Tick(10 * time.Minute) {
+			store.purge(time.Hour)
+		}
+	}()
+
+	return store
+}
+
+func (s *MemoryRateLimitStore) purge(idle time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, bucket := range s.buckets {
+		if time.Since(bucket.updated) > idle {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(policy.Limit)
+	perToken := policy.Window / time.Duration(policy.Limit)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updated))/float64(perToken))
+	bucket.updated = now
+
+	result := RateLimitResult{}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens -= 1
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) * float64(perToken))
+	}
+
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration((capacity - bucket.tokens) * float64(perToken))
+
+	return result, nil
+}
+
+// reads a policy such as "60/1m" from envVar, falling back when it is unset or invalid
+func NewRateLimitPolicy(name string, envVar string, fallback string) RateLimitPolicy {
+	rate := os.Getenv(envVar)
+	if rate == "" {
+		rate = fallback
+	}
+
+	limit, window, err := utils.ParseRate(rate)
+	if err != nil {
+		log.Println(envVar+":", err)
+		limit, window, _ = utils.ParseRate(fallback)
+	}
+
+	return RateLimitPolicy{Name: name, Limit: limit, Window: window}
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// Limits requests per user when logged in and per client IP otherwise, so it has to run after the
+// authorisation middleware of its group. X-RateLimit-Reset is the number of seconds until the bucket is full.
+func RateLimit(store RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := policy.Name + ":ip:" + c.ClientIP()
+
+		if userIDVal, exists := c.Get("user_id"); exists {
+			if userID, match := userIDVal.(int64); match {
+				key = policy.Name + ":user:" + strconv.FormatInt(userID, 10)
+			}
+		}
+
+		result, err := store.Take(key, policy)
+
+		// an unavailable store should not take the whole API down with it
+		if err != nil {
+			log.Println(err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", seconds(result.Reset))
+
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			c.JSON(429, gin.H{"error": "Too many requests"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
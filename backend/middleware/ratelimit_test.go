@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store unavailable")
+}
+
+func rateLimitRouter(store RateLimitStore, policy RateLimitPolicy) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user == "1" {
+			c.Set("user_id", int64(1))
+		}
+	})
+	router.Use(RateLimit(store, policy))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(204)
+	})
+
+	return router
+}
+
+func serveFrom(router *gin.Engine, ip string, user string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest("GET", "/", nil)
+	request.RemoteAddr = ip + ":1234"
+	if user != "" {
+		request.Header.Set("X-Test-User", user)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestRateLimit(t *testing.T) {
+	store := &MemoryRateLimitStore{buckets: map[string]*tokenBucket{}}
+	router := rateLimitRouter(store, RateLimitPolicy{Name: "test", Limit: 2, Window: time.Hour})
+
+	for i, remaining := range []string{"1", "0"} {
+		recorder := serveFrom(router, "192.0.2.1", "")
+
+		if recorder.Code != 204 || recorder.Header().Get("X-RateLimit-Remaining") != remaining {
+			t.Fatalf("request %d got %d with %s remaining", i, recorder.Code, recorder.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+
+	recorder := serveFrom(router, "192.0.2.1", "")
+	if recorder.Code != 429 {
+		t.Fatalf("request over the limit got %d", recorder.Code)
+	}
+
+	// a token comes back every half hour, the bucket is full again after an hour
+	if retry := recorder.Header().Get("Retry-After"); retry != "1800" {
+		t.Fatalf("Retry-After is %s, want 1800", retry)
+	}
+	if reset := recorder.Header().Get("X-RateLimit-Reset"); reset != "3600" {
+		t.Fatalf("X-RateLimit-Reset is %s, want 3600", reset)
+	}
+
+	if code := serveFrom(router, "192.0.2.2", "").Code; code != 204 {
+		t.Fatalf("another IP got %d", code)
+	}
+
+	// logged in users get their own bucket wherever they connect from
+	if code := serveFrom(router, "192.0.2.1", "1").Code; code != 204 {
+		t.Fatalf("logged in user got %d", code)
+	}
+
+	serveFrom(router, "192.0.2.3", "1")
+	if code := serveFrom(router, "192.0.2.4", "1").Code; code != 429 {
+		t.Fatalf("logged in user over the limit got %d", code)
+	}
+
+	store.buckets["test:ip:192.0.2.1"].updated = time.Now().Add(-31 * time.Minute)
+	if code := serveFrom(router, "192.0.2.1", "").Code; code != 204 {
+		t.Fatalf("request after a refill got %d", code)
+	}
+}
+
+func TestRateLimitStoreUnavailable(t *testing.T) {
+	router := rateLimitRouter(failingStore{}, RateLimitPolicy{Name: "test", Limit: 1, Window: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		if code := serveFrom(router, "192.0.2.1", "").Code; code != 204 {
+			t.Fatalf("request %d with the store down got %d", i, code)
+		}
+	}
+}
+
+func TestNewRateLimitPolicy(t *testing.T) {
+	cases := map[string]RateLimitPolicy{
+		"":       {Name: "test", Limit: 60, Window: time.Minute},
+		"5/10s":  {Name: "test", Limit: 5, Window: 10 * time.Second},
+		"5":      {Name: "test", Limit: 60, Window: time.Minute},
+		"0/1m":   {Name: "test", Limit: 60, Window: time.Minute},
+		"5/soon": {Name: "test", Limit: 60, Window: time.Minute},
+	}
+
+	for rate, want := range cases {
+		t.Setenv("TEST_RATE_LIMIT", rate)
+
+		if got := NewRateLimitPolicy("test", "TEST_RATE_LIMIT", "60/1m"); got != want {
+			t.Errorf("%q gave %+v, want %+v", rate, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"backend/auth"
+	"backend/database"
+	"backend/models"
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// path segments of routes no token may use: data exports, account deletion, private messages and account
+// settings (logins, linked identities, 2FA, tokens themselves)
+var sessionOnlySegments = []string{"exports", "deletion", "conversations", "messages", "tokens", "logins", "identities", "2fa"}
+
+// Returns the scope an access token needs for a route, or "" when no token may use it. Admin routes,
+// the owner's own account details and everything under sessionOnlySegments stay session-only.
+func requiredScope(method string, path string) string {
+	switch {
+	case strings.HasPrefix(path, "/logged_in/admin"):
+		return ""
+	case hasSessionOnlySegment(path):
+		return ""
+	case path == "/logged_in/users/:user_id" && (method == "GET" || method == "HEAD"):
+		return ""
+	case strings.HasPrefix(path, "/logged_in/moderation"):
+		return models.ScopeModerate
+	case method == "GET" || method == "HEAD":
+		return models.ScopeRead
+	case strings.Contains(path, "/comments"):
+		return models.ScopeWriteComments
+	case strings.HasPrefix(path, "/logged_in/topics"), strings.HasPrefix(path, "/logged_in/posts"):
+		return models.ScopeWritePosts
+	}
+
+	return ""
+}
+
+func hasSessionOnlySegment(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		for _, sessionOnly := range sessionOnlySegments {
+			if segment == sessionOnly {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// the token from an "Authorization: Bearer" header, if any
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+
+	return strings.TrimSpace(header[7:])
+}
+
+// looks up a bearer access token and records its use; nil when the token is unknown or expired
+func readAccessToken(db *sql.DB, tokenStr string) (*models.AccessToken, error) {
+	token, err := database.ReadAccessTokenByHash(db, auth.HashToken(tokenStr))
+
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	if err := database.UpdateAccessTokenLastUsedByID(db, token.ID); err != nil {
+		log.Println(err)
+	}
+
+	return token, nil
+}
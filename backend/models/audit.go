@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	ActorID    *int64          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType *string         `json:"target_type"`
+	TargetID   *int64          `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `json:"ip"`
+	Status     int             `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// zero values leave a field unfiltered
+type AuditFilter struct {
+	ActorID    int64
+	TargetType string
+	TargetID   int64
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// badge codes, the rules behind them are in the database package
+const (
+	BadgeFirstPost     = "first_post"
+	BadgeLikesReceived = "likes_received"
+	BadgeMemberYear    = "member_year"
+	BadgePopularPost   = "popular_post"
+)
+
+// a badge users can earn; Threshold is the number the rule compares against, if it has one
+type Badge struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Threshold   int    `json:"threshold,omitempty"`
+}
+
+// a badge awarded to a user. Source points at what earned it, such as the popular post.
+type UserBadge struct {
+	UserID      int64     `json:"user_id"`
+	Username    string    `json:"username"`
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	SourceType  string    `json:"source_type"`
+	SourceID    *int64    `json:"source_id"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Block struct {
+	ID        int64     `json:"id"`
+	BlockerID int64     `json:"blocker_id"`
+	BlockedID int64     `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Mute struct {
+	ID        int64     `json:"id"`
+	MuterID   int64     `json:"muter_id"`
+	MutedID   int64     `json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RelatedUser struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
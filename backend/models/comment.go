@@ -13,6 +13,8 @@ type Comment struct {
 	CreatedBy       int64     `json:"created_by"`
 	CreatedAt       time.Time `json:"created_at"`
 	Username        string    `json:"username"`
+	IsCollapsed     bool      `json:"is_collapsed"`
+	IsAccepted      bool      `json:"is_accepted"`
 }
 
 type CreateCommentInput struct {
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// what happens to a deleted account's posts, comments, topics and messages
+const (
+	DeletionAnonymize = "anonymize"
+	DeletionDelete    = "delete"
+)
+
+// an account deletion waiting out its cooling-off period
+type AccountDeletion struct {
+	UserID       int64     `json:"user_id"`
+	Mode         string    `json:"mode"`
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+type DeleteAccountInput struct {
+	Mode string `json:"mode"`
+}
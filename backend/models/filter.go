@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// actions taken when a blocked term matches
+const (
+	BlockedTermActionHold   = "hold"
+	BlockedTermActionReject = "reject"
+)
+
+// held content statuses
+const (
+	HeldStatusPending  = "pending"
+	HeldStatusApproved = "approved"
+	HeldStatusRejected = "rejected"
+)
+
+type BlockedTerm struct {
+	ID        int64     `json:"id"`
+	Pattern   string    `json:"pattern"`
+	IsRegex   bool      `json:"is_regex"`
+	Action    string    `json:"action"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateBlockedTermInput struct {
+	Pattern string `json:"pattern"`
+	IsRegex bool   `json:"is_regex"`
+	Action  string `json:"action"`
+}
+
+// Payload holds the topic, post or comment exactly as it will be created once approved
+type HeldContent struct {
+	ID         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	UserID     int64           `json:"user_id"`
+	Payload    json.RawMessage `json:"payload"`
+	Reason     string          `json:"reason"`
+	Status     string          `json:"status"`
+	ReviewedBy *int64          `json:"reviewed_by"`
+	ReviewedAt *time.Time      `json:"reviewed_at"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
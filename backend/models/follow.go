@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type Follow struct {
+	ID         int64     `json:"id"`
+	FollowerID int64     `json:"follower_id"`
+	FolloweeID int64     `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FollowUser struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// an account at an external OpenID Connect provider linked to a user
+type Identity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// an OIDC login in progress between redirecting to the provider and its callback
+type OIDCState struct {
+	StateHash    string
+	BrowserHash  string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	LinkUserID   *int64
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type OIDCSignupInput struct {
+	SignupToken string `json:"signup_token"`
+	Username    string `json:"username"`
+}
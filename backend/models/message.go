@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type Conversation struct {
+	ID            int64                     `json:"id"`
+	CreatedBy     int64                     `json:"created_by"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	LastMessageAt time.Time                 `json:"last_message_at"`
+	UnreadCount   int                       `json:"unread_count"`
+	Participants  []ConversationParticipant `json:"participants"`
+}
+
+type ConversationParticipant struct {
+	UserID            int64      `json:"user_id"`
+	Username          string     `json:"username"`
+	LastReadMessageID *int64     `json:"last_read_message_id"`
+	LastReadAt        *time.Time `json:"last_read_at"`
+	JoinedAt          time.Time  `json:"joined_at"`
+}
+
+type CreateConversationInput struct {
+	ParticipantIDs []int64 `json:"participant_ids"`
+}
+
+type Message struct {
+	ID             int64     `json:"id"`
+	ConversationID int64     `json:"conversation_id"`
+	SenderID       int64     `json:"sender_id"`
+	Body           string    `json:"body"`
+	IsEdited       int       `json:"is_edited"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type CreateMessageInput struct {
+	Body string `json:"body"`
+}
+
+type UpdateMessageInput struct {
+	Body *string `json:"body"`
+}
+
+type ReadReceiptInput struct {
+	MessageID int64 `json:"message_id"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// a poll attached to a post. Votes and Voters are only filled in when the viewer may see the results.
+type Poll struct {
+	ID             int64        `json:"id"`
+	PostID         int64        `json:"post_id"`
+	Question       string       `json:"question"`
+	MultipleChoice bool         `json:"multiple_choice"`
+	PublicVotes    bool         `json:"public_votes"`
+	HideResults    bool         `json:"hide_results"`
+	ClosesAt       *time.Time   `json:"closes_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	Options        []PollOption `json:"options"`
+	TotalVoters    int          `json:"total_voters"`
+	MyVotes        []int64      `json:"my_votes"`
+	IsClosed       bool         `json:"is_closed"`
+	ResultsHidden  bool         `json:"results_hidden"`
+}
+
+type PollOption struct {
+	ID       int64       `json:"id"`
+	Position int         `json:"position"`
+	Text     string      `json:"text"`
+	Votes    int         `json:"votes"`
+	Voters   []PollVoter `json:"voters,omitempty"`
+}
+
+type PollVoter struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// HideResults keeps the counts from anyone who hasn't voted until the poll closes
+type CreatePollInput struct {
+	Question       string     `json:"question"`
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	PublicVotes    bool       `json:"public_votes"`
+	HideResults    bool       `json:"hide_results"`
+	ClosesAt       *time.Time `json:"closes_at"`
+}
+
+type PollVoteInput struct {
+	OptionIDs []int64 `json:"option_ids"`
+}
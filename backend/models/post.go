@@ -3,24 +3,27 @@ package models
 import "time"
 
 type Post struct {
-	ID          int64     `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	TopicID     int64     `json:"topic_id"`
-	Likes       int       `json:"likes"`
-	Dislikes    int       `json:"dislikes"`
-	IsEdited    int       `json:"is_edited"`
-	Views       int       `json:"views"`
-	Popularity  int       `json:"popularity"`
-	CreatedBy   int64     `json:"created_by"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                int64     `json:"id"`
+	Title             string    `json:"title"`
+	Description       string    `json:"description"`
+	TopicID           int64     `json:"topic_id"`
+	Likes             int       `json:"likes"`
+	Dislikes          int       `json:"dislikes"`
+	IsEdited          int       `json:"is_edited"`
+	Views             int       `json:"views"`
+	Popularity        int       `json:"popularity"`
+	CreatedBy         int64     `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+	AcceptedCommentID *int64    `json:"accepted_comment_id"`
+	IsAnswered        bool      `json:"is_answered"`
+	Poll              *Poll     `json:"poll,omitempty"`
 }
 
 type CreatePostInput struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	TopicID     int64  `json:"topic_id"`
-	CreatedBy   int64  `json:"created_by"`
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	TopicID     int64            `json:"topic_id"`
+	Poll        *CreatePollInput `json:"poll"`
 }
 
 type UpdatePostInput struct {
@@ -43,3 +46,7 @@ type PostReaction struct {
 type CreatePostReactionInput struct {
 	Reaction bool `json:"reaction"`
 }
+
+type AcceptAnswerInput struct {
+	CommentID int64 `json:"comment_id"`
+}
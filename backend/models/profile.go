@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// what anyone can see about a user
+type UserProfile struct {
+	ID             int64             `json:"id"`
+	Username       string            `json:"username"`
+	DisplayName    string            `json:"display_name"`
+	Bio            string            `json:"bio"`
+	AvatarURL      string            `json:"avatar_url"`
+	Location       string            `json:"location"`
+	Website        string            `json:"website"`
+	CreatedAt      time.Time         `json:"created_at"`
+	PostCount      int               `json:"post_count"`
+	CommentCount   int               `json:"comment_count"`
+	LikesReceived  int               `json:"likes_received"`
+	Reputation     int               `json:"reputation"`
+	HideActivity   bool              `json:"hide_activity"`
+	Badges         []UserBadge       `json:"badges"`
+	RecentActivity []ProfileActivity `json:"recent_activity"`
+}
+
+// a post or comment in a user's recent activity
+type ProfileActivity struct {
+	Type      string    `json:"type"`
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	PostTitle string    `json:"post_title"`
+	Excerpt   string    `json:"excerpt"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// a reaction the user gave to a post or comment
+type UserReaction struct {
+	TargetType string    `json:"target_type"`
+	TargetID   int64     `json:"target_id"`
+	PostID     int64     `json:"post_id"`
+	PostTitle  string    `json:"post_title"`
+	Reaction   bool      `json:"reaction"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// report target types
+const (
+	TargetTypePost    = "post"
+	TargetTypeComment = "comment"
+	TargetTypeTopic   = "topic"
+	TargetTypeUser    = "user"
+)
+
+// moderator actions on reported targets
+const (
+	ModerationActionDismiss = "dismiss"
+	ModerationActionRemove  = "remove"
+	ModerationActionWarn    = "warn"
+	ModerationActionSuspend = "suspend"
+)
+
+var ReportReasonCodes = []string{"spam", "abuse", "harassment", "off_topic", "illegal", "other"}
+
+type Report struct {
+	ID             int64      `json:"id"`
+	ReporterID     int64      `json:"reporter_id"`
+	TargetType     string     `json:"target_type"`
+	TargetID       int64      `json:"target_id"`
+	ReasonCode     string     `json:"reason_code"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status"`
+	Resolution     *string    `json:"resolution"`
+	ResolutionNote *string    `json:"resolution_note"`
+	ResolvedBy     *int64     `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type CreateReportInput struct {
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	ReasonCode string `json:"reason_code"`
+	Details    string `json:"details"`
+}
+
+type ReportGroup struct {
+	TargetType      string    `json:"target_type"`
+	TargetID        int64     `json:"target_id"`
+	ReportCount     int       `json:"report_count"`
+	ReasonCodes     []string  `json:"reason_codes"`
+	FirstReportedAt time.Time `json:"first_reported_at"`
+	LastReportedAt  time.Time `json:"last_reported_at"`
+}
+
+type ModerationActionInput struct {
+	Action      string `json:"action"`
+	Note        string `json:"note"`
+	SuspendDays int    `json:"suspend_days"`
+}
+
+type Warning struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Reason    string    `json:"reason"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
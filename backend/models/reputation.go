@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// why a user's reputation changed. Reaction points are applied by the reaction triggers in the schema:
+// +10/-2 for a like/dislike on a post, +5/-1 on a comment, reversed when the reaction is removed.
+const (
+	ReputationPostLiked       = "post_liked"
+	ReputationPostDisliked    = "post_disliked"
+	ReputationCommentLiked    = "comment_liked"
+	ReputationCommentDisliked = "comment_disliked"
+	ReputationAnswerAccepted  = "answer_accepted"
+	ReputationPenalty         = "penalty"
+)
+
+const AnswerAcceptedPoints = 15
+
+// privileges that need a minimum reputation, moderators and admins have them all
+const (
+	PrivilegeCreateTopic = "create_topic"
+	PrivilegeDislike     = "dislike"
+)
+
+// one entry in a user's reputation history
+type ReputationEvent struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Points     int       `json:"points"`
+	Reason     string    `json:"reason"`
+	SourceType string    `json:"source_type"`
+	SourceID   *int64    `json:"source_id"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CreateReputationPenaltyInput struct {
+	Points int    `json:"points"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// a suspension without an expiry is a permanent ban
+type Suspension struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedBy int64      `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	LiftedAt  *time.Time `json:"lifted_at"`
+}
+
+type CreateSuspensionInput struct {
+	Reason    string `json:"reason"`
+	Days      int    `json:"days"`
+	Permanent bool   `json:"permanent"`
+}
+
+type BannedIP struct {
+	ID        int64     `json:"id"`
+	CIDR      string    `json:"cidr"`
+	Reason    string    `json:"reason"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateBannedIPInput struct {
+	CIDR   string `json:"cidr"`
+	Reason string `json:"reason"`
+}
+
+type BannedUsername struct {
+	ID        int64     `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Reason    string    `json:"reason"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateBannedUsernameInput struct {
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason"`
+}
@@ -8,15 +8,18 @@ type Topic struct {
 	Description string    `json:"description"`
 	CreatedBy   int64     `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
+	QAMode      bool      `json:"qa_mode"`
 }
 
 type CreateTopicInput struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	CreatedBy   int64  `json:"created_by"`
+	QAMode      bool   `json:"qa_mode"`
 }
 
 type UpdateTopicInput struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
+	QAMode      *bool   `json:"qa_mode"`
 }
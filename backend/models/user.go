@@ -2,27 +2,112 @@ package models
 
 import "time"
 
+// user roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	Password     string    `json:"-"`
-	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-	LastActive   time.Time `json:"last_active"`
+	ID            int64     `json:"id"`
+	Username      string    `json:"username"`
+	Password      string    `json:"-"`
+	PasswordHash  string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastActive    time.Time `json:"last_active"`
+	Role          string    `json:"role"`
+	TOTPEnabled   bool      `json:"totp_enabled"`
+	Email         *string   `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	AvatarURL     string    `json:"avatar_url"`
+	Location      string    `json:"location"`
+	Website       string    `json:"website"`
+	HideActivity  bool      `json:"hide_activity"`
 }
 
 type CreateUserInput struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
 }
 
 type UpdateUserInput struct {
-	Username   *string    `json:"username"`
-	Password   *string    `json:"password"`
-	LastActive *time.Time `json:"last_active"`
+	Username     *string    `json:"username"`
+	Password     *string    `json:"password"`
+	LastActive   *time.Time `json:"last_active"`
+	Email        *string    `json:"email"`
+	DisplayName  *string    `json:"display_name"`
+	Bio          *string    `json:"bio"`
+	AvatarURL    *string    `json:"avatar_url"`
+	Location     *string    `json:"location"`
+	Website      *string    `json:"website"`
+	HideActivity *bool      `json:"hide_activity"`
+}
+
+type UpdateUserRoleInput struct {
+	Role string `json:"role"`
 }
 
 type LoginUserData struct {
 	Password string `json:"password"`
 	Username string `json:"username"`
 }
+
+type LoginAttempt struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	UserID    *int64    `json:"user_id"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Pending   bool      `json:"pending"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TOTPCodeInput struct {
+	Code string `json:"code"`
+}
+
+type DisableTOTPInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type LoginTwoFactorInput struct {
+	PendingToken string `json:"pending_token"`
+	Code         string `json:"code"`
+}
+
+// purposes of single-use tokens sent by email
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// the token itself is only ever sent to the user, TokenHash is what gets stored
+type UserToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Purpose   string     `json:"purpose"`
+	TokenHash string     `json:"-"`
+	Email     string     `json:"email"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// watch levels
+const (
+	WatchLevelAll     = "all"
+	WatchLevelReplies = "replies"
+	WatchLevelMuted   = "muted"
+)
+
+type TopicWatch struct {
+	ID        int64     `json:"id"`
+	TopicID   int64     `json:"topic_id"`
+	UserID    int64     `json:"user_id"`
+	Level     string    `json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PostWatch struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	UserID    int64     `json:"user_id"`
+	Level     string    `json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateWatchInput struct {
+	Level string `json:"level"`
+}
+
+type WatchedPost struct {
+	Post
+	LastActivity time.Time `json:"last_activity"`
+}
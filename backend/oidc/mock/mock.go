@@ -0,0 +1,174 @@
+// Package mock is a minimal OpenID Connect provider for trying the OIDC login locally and in tests.
+// It approves every authorization request straight away, for the user named by the sub, email and
+// preferred_username query parameters of the authorization URL (defaults below).
+package mock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	subject       string
+	email         string
+	username      string
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// NewHandler serves a provider whose issuer is the given URL, signing ID tokens with a fresh RSA key
+func NewHandler(issuer string) (http.Handler, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	codes := map[string]authorization{}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/authorize",
+			"token_endpoint":                        issuer + "/token",
+			"jwks_uri":                              issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"S256"},
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "mock",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+			http.Error(w, "only the authorization code flow with S256 PKCE is supported", 400)
+			return
+		}
+
+		redirect, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil || redirect.Scheme == "" {
+			http.Error(w, "invalid redirect_uri", 400)
+			return
+		}
+
+		auth := authorization{
+			clientID:      query.Get("client_id"),
+			redirectURI:   query.Get("redirect_uri"),
+			nonce:         query.Get("nonce"),
+			codeChallenge: query.Get("code_challenge"),
+			subject:       query.Get("sub"),
+			email:         query.Get("email"),
+			username:      query.Get("preferred_username"),
+		}
+
+		if auth.subject == "" {
+			auth.subject = "mock-user"
+		}
+		if auth.email == "" {
+			auth.email = auth.subject + "@example.com"
+		}
+		if auth.username == "" {
+			auth.username = auth.subject
+		}
+
+		codeBytes := make([]byte, 16)
+		rand.Read(codeBytes)
+		code := base64.RawURLEncoding.EncodeToString(codeBytes)
+
+		mu.Lock()
+		codes[code] = auth
+		mu.Unlock()
+
+		params := redirect.Query()
+		params.Set("code", code)
+		params.Set("state", query.Get("state"))
+		redirect.RawQuery = params.Encode()
+
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.ParseForm() != nil {
+			writeJSON(w, 400, map[string]string{"error": "invalid_request"})
+			return
+		}
+
+		mu.Lock()
+		auth, exists := codes[r.PostForm.Get("code")]
+		delete(codes, r.PostForm.Get("code"))
+		mu.Unlock()
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		if !exists || r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("client_id") != auth.clientID || r.PostForm.Get("redirect_uri") != auth.redirectURI ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+			writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                issuer,
+			"aud":                auth.clientID,
+			"sub":                auth.subject,
+			"email":              auth.email,
+			"email_verified":     true,
+			"preferred_username": auth.username,
+			"nonce":              auth.nonce,
+			"iat":                now.Unix(),
+			"exp":                now.Add(5 * time.Minute).Unix(),
+		})
+		token.Header["kid"] = "mock"
+
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			writeJSON(w, 500, map[string]string{"error": "server_error"})
+			return
+		}
+
+		writeJSON(w, 200, map[string]interface{}{
+			"access_token": idToken,
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     idToken,
+		})
+	})
+
+	return mux, nil
+}
@@ -0,0 +1,250 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnknownProvider = errors.New("unknown oidc provider")
+var ErrInsecureIssuer = errors.New("oidc issuer must use https")
+
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+var providers map[string]*Provider
+var providersOnce sync.Once
+
+// Providers are configured from the environment. OIDC_PROVIDERS lists their names, and each name
+// (upper-cased) has its own variables:
+//
+//	OIDC_<NAME>_ISSUER         e.g. https://accounts.google.com
+//	OIDC_<NAME>_CLIENT_ID
+//	OIDC_<NAME>_CLIENT_SECRET  optional for public clients, PKCE is always used
+//	OIDC_<NAME>_REDIRECT_URL   the frontend page the provider sends the user back to
+//	OIDC_<NAME>_SCOPES         default "openid email profile"
+//
+// Issuers must use https, except on localhost so a local mock provider can be used.
+func loadProviders() map[string]*Provider {
+	loaded := map[string]*Provider{}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		scopes := strings.Fields(os.Getenv(prefix + "SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		provider := &Provider{
+			Name:         name,
+			Issuer:       strings.TrimRight(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       scopes,
+			client:       &http.Client{Timeout: 10 * time.Second},
+		}
+
+		if provider.Issuer == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Println("OIDC provider " + name + " is missing its issuer, client ID or redirect URL")
+			continue
+		}
+
+		if err := checkSecureURL(provider.Issuer); err != nil {
+			log.Println("OIDC provider "+name+":", err)
+			continue
+		}
+
+		loaded[name] = provider
+	}
+
+	return loaded
+}
+
+func ProviderByName(name string) (*Provider, error) {
+	providersOnce.Do(func() {
+		providers = loadProviders()
+	})
+
+	provider, exists := providers[name]
+	if !exists {
+		return nil, ErrUnknownProvider
+	}
+
+	return provider, nil
+}
+
+func ProviderNames() []string {
+	providersOnce.Do(func() {
+		providers = loadProviders()
+	})
+
+	names := []string{}
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func checkSecureURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if parsed.Scheme == "https" {
+		return nil
+	}
+
+	host := parsed.Hostname()
+	if parsed.Scheme == "http" && (host == "localhost" || host == "127.0.0.1" || host == "::1") {
+		return nil
+	}
+
+	return ErrInsecureIssuer
+}
+
+func (p *Provider) getJSON(endpoint string, target interface{}) error {
+	res, err := p.client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return fmt.Errorf("oidc: GET %s returned %d", endpoint, res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(target)
+}
+
+// fetches the provider metadata once; a failed fetch is retried on the next call
+func (p *Provider) metadata() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var fetched discovery
+	if err := p.getJSON(p.Issuer+"/.well-known/openid-configuration", &fetched); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimRight(fetched.Issuer, "/") != p.Issuer {
+		return nil, errors.New("oidc: discovered issuer does not match the configured issuer")
+	}
+
+	for _, endpoint := range []string{fetched.AuthorizationEndpoint, fetched.TokenEndpoint, fetched.JWKSURI} {
+		if err := checkSecureURL(endpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	p.discovery = &fetched
+
+	return p.discovery, nil
+}
+
+// the URL to send the user to, carrying the state, nonce and PKCE challenge for this login
+func (p *Provider) AuthCodeURL(state string, nonce string, codeChallenge string) (string, error) {
+	meta, err := p.metadata()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", p.RedirectURL)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return meta.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// trades an authorization code for the ID token
+func (p *Provider) Exchange(code string, codeVerifier string) (string, error) {
+	meta, err := p.metadata()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+
+	res, err := p.client.PostForm(meta.TokenEndpoint, form)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&tokens); err != nil {
+		return "", err
+	}
+
+	if res.StatusCode != 200 || tokens.IDToken == "" {
+		return "", fmt.Errorf("oidc: token exchange failed: %s %s", tokens.Error, tokens.ErrorDescription)
+	}
+
+	return tokens.IDToken, nil
+}
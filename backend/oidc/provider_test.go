@@ -0,0 +1,95 @@
+package oidc
+
+import (
+	"backend/oidc/mock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestProviderWithMock(t *testing.T) {
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	var err error
+	if handler, err = mock.NewHandler(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &Provider{
+		Name:        "mock",
+		Issuer:      server.URL,
+		ClientID:    "chatit",
+		RedirectURL: "http://localhost:3000/oidc/callback",
+		Scopes:      []string{"openid", "email"},
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+
+	state, _ := RandomString()
+	nonce, _ := RandomString()
+	verifier, _ := RandomString()
+
+	authURL, err := provider.AuthCodeURL(state, nonce, CodeChallenge(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL + "&sub=alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if location.Query().Get("state") != state {
+		t.Fatalf("state %q came back as %q", state, location.Query().Get("state"))
+	}
+
+	code := location.Query().Get("code")
+
+	// the code is bound to the PKCE verifier
+	if _, err := provider.Exchange(code, "wrong-verifier"); err == nil {
+		t.Fatal("exchange with the wrong verifier succeeded")
+	}
+
+	res, err = client.Get(authURL + "&sub=alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	location, _ = url.Parse(res.Header.Get("Location"))
+
+	rawIDToken, err := provider.Exchange(location.Query().Get("code"), verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Verify(rawIDToken, "other-nonce"); err == nil {
+		t.Fatal("ID token accepted with another login's nonce")
+	}
+
+	claims, err := provider.Verify(rawIDToken, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "alice" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
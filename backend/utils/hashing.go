@@ -1,14 +1,222 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashingPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword(
-		[]byte(password),
-		bcrypt.DefaultCost,
-	)
+var ErrUnknownHash = errors.New("unrecognised password hash")
+
+// A password hashing scheme. Verify only has to handle hashes the hasher produced itself, and
+// NeedsRehash reports whether such a hash was made with other parameters than the current ones.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(hash string, password string) (bool, error)
+	Handles(hash string) bool
+	NeedsRehash(hash string) bool
+}
+
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
 
 	return string(bytes), err
 }
+
+func (h BcryptHasher) Verify(hash string, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (h BcryptHasher) Handles(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	if !h.Handles(hash) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != h.Cost
+}
+
+// argon2id hashes are stored in the PHC string format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2Hash struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func parseArgon2Hash(hash string) (*argon2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" || parts[2] != "v=19" {
+		return nil, ErrUnknownHash
+	}
+
+	parsed := argon2Hash{}
+
+	var parallelism uint32
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.memory, &parsed.iterations, &parallelism); err != nil {
+		return nil, ErrUnknownHash
+	}
+	parsed.parallelism = uint8(parallelism)
+
+	var err error
+	if parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownHash
+	}
+	if parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, ErrUnknownHash
+	}
+
+	return &parsed, nil
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.Memory,
+		h.Iterations,
+		h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// verifies with the parameters stored in the hash, not the current ones
+func (h Argon2idHasher) Verify(hash string, password string) (bool, error) {
+	parsed, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), parsed.salt, parsed.iterations, parsed.memory, parsed.parallelism, uint32(len(parsed.key)))
+
+	return subtle.ConstantTimeCompare(key, parsed.key) == 1, nil
+}
+
+func (h Argon2idHasher) Handles(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parseArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+
+	return parsed.memory != h.Memory || parsed.iterations != h.Iterations || parsed.parallelism != h.Parallelism ||
+		uint32(len(parsed.salt)) != h.SaltLength || uint32(len(parsed.key)) != h.KeyLength
+}
+
+var hasher Hasher
+var knownHashers []Hasher
+var hasherOnce sync.Once
+
+func envUint(name string, fallback uint64, bits int) uint64 {
+	value, err := strconv.ParseUint(os.Getenv(name), 10, bits)
+	if err != nil || value == 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// New passwords are hashed with PASSWORD_HASHER (argon2id by default, or bcrypt). Hashes from the other
+// scheme still verify, and get replaced on the user's next login.
+//
+//	ARGON2_MEMORY=65536 (KiB)  ARGON2_ITERATIONS=3  ARGON2_PARALLELISM=2
+//	BCRYPT_COST=10
+func loadHashers() {
+	argon := Argon2idHasher{
+		Memory:      uint32(envUint("ARGON2_MEMORY", 64*1024, 32)),
+		Iterations:  uint32(envUint("ARGON2_ITERATIONS", 3, 32)),
+		Parallelism: uint8(envUint("ARGON2_PARALLELISM", 2, 8)),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+
+	cost := int(envUint("BCRYPT_COST", uint64(bcrypt.DefaultCost), 8))
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		log.Println("BCRYPT_COST out of range, using the default")
+		cost = bcrypt.DefaultCost
+	}
+	bcryptHasher := BcryptHasher{Cost: cost}
+
+	knownHashers = []Hasher{argon, bcryptHasher}
+
+	switch os.Getenv("PASSWORD_HASHER") {
+	case "bcrypt":
+		hasher = bcryptHasher
+	case "", "argon2id":
+		hasher = argon
+	default:
+		log.Println("Unknown PASSWORD_HASHER, using argon2id")
+		hasher = argon
+	}
+}
+
+func HashingPassword(password string) (string, error) {
+	hasherOnce.Do(loadHashers)
+
+	return hasher.Hash(password)
+}
+
+// checks a password against a hash from any supported scheme
+func VerifyPassword(hash string, password string) (bool, error) {
+	hasherOnce.Do(loadHashers)
+
+	for _, known := range knownHashers {
+		if known.Handles(hash) {
+			return known.Verify(hash, password)
+		}
+	}
+
+	return false, ErrUnknownHash
+}
+
+// reports whether a hash should be replaced with one from the current scheme and parameters
+func NeedsRehash(hash string) bool {
+	hasherOnce.Do(loadHashers)
+
+	return hasher.NeedsRehash(hash)
+}
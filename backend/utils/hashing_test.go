@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// parameters small enough to keep the tests fast
+var testArgon = Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+var testBcrypt = BcryptHasher{Cost: bcrypt.MinCost}
+
+// loads the package hashers from the given environment, restoring the previous ones afterwards
+func useHashers(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	hasherOnce.Do(func() {})
+
+	previous, previousKnown := hasher, knownHashers
+	t.Cleanup(func() { hasher, knownHashers = previous, previousKnown })
+
+	for _, name := range []string{"PASSWORD_HASHER", "ARGON2_MEMORY", "ARGON2_ITERATIONS", "ARGON2_PARALLELISM", "BCRYPT_COST"} {
+		t.Setenv(name, env[name])
+	}
+
+	loadHashers()
+}
+
+func TestHashers(t *testing.T) {
+	for _, h := range []Hasher{testArgon, testBcrypt} {
+		hash, err := h.Hash("correct horse battery staple")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !h.Handles(hash) || h.NeedsRehash(hash) {
+			t.Fatalf("%T does not recognise its own hash", h)
+		}
+
+		if ok, err := h.Verify(hash, "correct horse battery staple"); !ok || err != nil {
+			t.Fatalf("%T rejected the right password: %v", h, err)
+		}
+
+		if ok, err := h.Verify(hash, "correct horse battery stapler"); ok || err != nil {
+			t.Fatalf("%T accepted the wrong password: %v", h, err)
+		}
+
+		again, err := h.Hash("correct horse battery staple")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if again == hash {
+			t.Fatalf("%T hashes are not salted", h)
+		}
+	}
+}
+
+func TestArgon2idHash(t *testing.T) {
+	hash, err := testArgon.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash %q is not in the PHC format", hash)
+	}
+
+	// old hashes verify with their own parameters, but are flagged for rehashing
+	stronger := testArgon
+	stronger.Iterations = 2
+
+	if ok, err := stronger.Verify(hash, "password"); !ok || err != nil {
+		t.Fatalf("hash with older parameters rejected: %v", err)
+	}
+
+	if !stronger.NeedsRehash(hash) {
+		t.Fatalf("hash with older parameters not flagged for rehashing")
+	}
+
+	for _, malformed := range []string{"", "$argon2id$v=19$m=1024,t=1,p=1$salt", "$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=x$c2FsdA$a2V5"} {
+		if _, err := testArgon.Verify(malformed, "password"); err != ErrUnknownHash {
+			t.Errorf("malformed hash %q gave %v", malformed, err)
+		}
+
+		if !testArgon.NeedsRehash(malformed) {
+			t.Errorf("malformed hash %q not flagged for rehashing", malformed)
+		}
+	}
+}
+
+func TestBcryptNeedsRehash(t *testing.T) {
+	hash, err := testBcrypt.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !(BcryptHasher{Cost: bcrypt.MinCost + 1}).NeedsRehash(hash) {
+		t.Fatalf("hash with a lower cost not flagged for rehashing")
+	}
+
+	if !testBcrypt.NeedsRehash("$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$a2V5") {
+		t.Fatalf("argon2id hash not flagged for rehashing to bcrypt")
+	}
+}
+
+// hashes from either scheme verify whichever one is current, and the other scheme's get rehashed
+func TestVerifyPasswordAcrossSchemes(t *testing.T) {
+	argonHash, err := testArgon.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcryptHash, err := testBcrypt.Hash("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheap := map[string]string{"ARGON2_MEMORY": "1024", "ARGON2_ITERATIONS": "1", "ARGON2_PARALLELISM": "1", "BCRYPT_COST": "4"}
+
+	for _, scheme := range []string{"argon2id", "bcrypt"} {
+		cheap["PASSWORD_HASHER"] = scheme
+		useHashers(t, cheap)
+
+		for _, hash := range []string{argonHash, bcryptHash} {
+			if ok, err := VerifyPassword(hash, "password"); !ok || err != nil {
+				t.Fatalf("%s: hash %q rejected: %v", scheme, hash, err)
+			}
+
+			if ok, _ := VerifyPassword(hash, "wrong"); ok {
+				t.Fatalf("%s: hash %q accepted the wrong password", scheme, hash)
+			}
+		}
+
+		current, err := HashingPassword("password")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if NeedsRehash(current) {
+			t.Fatalf("%s: fresh hash flagged for rehashing", scheme)
+		}
+
+		if NeedsRehash(argonHash) == (scheme == "argon2id") || NeedsRehash(bcryptHash) == (scheme == "bcrypt") {
+			t.Fatalf("%s: only the other scheme's hashes should need rehashing", scheme)
+		}
+	}
+
+	if _, err := VerifyPassword("plaintext", "plaintext"); err != ErrUnknownHash {
+		t.Fatalf("unknown hash gave %v", err)
+	}
+}
+
+func TestLoadHashersFallback(t *testing.T) {
+	useHashers(t, map[string]string{"PASSWORD_HASHER": "md5", "BCRYPT_COST": "99"})
+
+	if _, match := hasher.(Argon2idHasher); !match {
+		t.Fatalf("unknown PASSWORD_HASHER did not fall back to argon2id")
+	}
+
+	if knownHashers[1].(BcryptHasher).Cost != bcrypt.DefaultCost {
+		t.Fatalf("out of range BCRYPT_COST was used")
+	}
+}
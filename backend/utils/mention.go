@@ -0,0 +1,20 @@
+package utils
+
+import "regexp"
+
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9_.-]+)`)
+
+// returns the distinct usernames mentioned as @username in text
+func ExtractMentions(text string) []string {
+	seen := map[string]bool{}
+	usernames := []string{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			usernames = append(usernames, match[1])
+		}
+	}
+
+	return usernames
+}
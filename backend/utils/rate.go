@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("rate must look like 60/1m")
+
+// parses a rate such as "60/1m" into a count and the window it applies to
+func ParseRate(rate string) (int, time.Duration, error) {
+	countStr, windowStr, found := strings.Cut(strings.TrimSpace(rate), "/")
+	if !found {
+		return 0, 0, ErrInvalidRate
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return 0, 0, ErrInvalidRate
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return 0, 0, ErrInvalidRate
+	}
+
+	return count, window, nil
+}